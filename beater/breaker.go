@@ -0,0 +1,62 @@
+package beater
+
+import (
+	"sync"
+	"time"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+// CircuitBreaker counts the consecutive failed requests to an admin server.
+// Once open it rejects every request until the cool-down is over, then lets
+// the requests through again and opens at the first new failure.
+type CircuitBreaker struct {
+	config config.Breaker
+
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker.
+func NewCircuitBreaker(cfg config.Breaker) *CircuitBreaker {
+	return &CircuitBreaker{config: cfg}
+}
+
+// Allow returns false while the breaker is open.
+func (b *CircuitBreaker) Allow() bool {
+	if !b.config.Enabled {
+		return true
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+// Success closes the breaker. It returns true if there were failures.
+func (b *CircuitBreaker) Success() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	recovered := b.failures >= b.config.Failures
+	b.failures = 0
+	b.openUntil = time.Time{}
+	return recovered
+}
+
+// Failure counts a failed request and opens the breaker when the limit of
+// consecutive failures is reached, or again when a request fails after the
+// cool-down. It returns true every time the breaker opens.
+func (b *CircuitBreaker) Failure() bool {
+	if !b.config.Enabled {
+		return false
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+	if b.failures < b.config.Failures || time.Now().Before(b.openUntil) {
+		return false
+	}
+	b.openUntil = time.Now().Add(b.config.Cooldown)
+	return true
+}
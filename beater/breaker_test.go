@@ -0,0 +1,55 @@
+// +build !integration
+
+package beater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := NewCircuitBreaker(config.Breaker{Enabled: false, Failures: 1, Cooldown: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if breaker.Failure() {
+			t.Fatalf("disabled breaker opened after %d failures", i+1)
+		}
+		if !breaker.Allow() {
+			t.Fatalf("disabled breaker rejected a request after %d failures", i+1)
+		}
+	}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	cooldown := 50 * time.Millisecond
+	breaker := NewCircuitBreaker(config.Breaker{Enabled: true, Failures: 2, Cooldown: cooldown})
+
+	steps := []struct {
+		name   string
+		action func() bool
+		want   bool
+	}{
+		{"closed allows", breaker.Allow, true},
+		{"first failure keeps it closed", breaker.Failure, false},
+		{"still allows", breaker.Allow, true},
+		{"second failure opens", breaker.Failure, true},
+		{"open rejects", breaker.Allow, false},
+		{"failure while open is not a new opening", breaker.Failure, false},
+		{"wait for the cool-down", func() bool { time.Sleep(cooldown + 10*time.Millisecond); return true }, true},
+		{"half open allows", breaker.Allow, true},
+		{"failure after the cool-down opens again", breaker.Failure, true},
+		{"open again rejects", breaker.Allow, false},
+		{"wait for the cool-down again", func() bool { time.Sleep(cooldown + 10*time.Millisecond); return true }, true},
+		{"success after failures recovers", breaker.Success, true},
+		{"closed after success", breaker.Allow, true},
+		{"success without failures", breaker.Success, false},
+		{"failures count from zero again", breaker.Failure, false},
+	}
+	for _, step := range steps {
+		if got := step.action(); got != step.want {
+			t.Fatalf("%s: got %v, want %v", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package beater
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Weblogic versions with a registered set of collectors.
+const (
+	WlsVersion1212 = "12.1.2"
+	WlsVersion122  = "12.2"
+)
+
+// Collector gathers one family of metrics (server, datasource, ...) from a
+// weblogic domain. It adds a task to the cycle for every request, the events
+// returned by the tasks are published once the cycle is complete.
+type Collector interface {
+	Collect(cycle *Cycle)
+}
+
+// CollectorFunc adapts an ordinary function to the Collector interface.
+type CollectorFunc func(cycle *Cycle)
+
+// Collect calls f(cycle).
+func (f CollectorFunc) Collect(cycle *Cycle) {
+	f(cycle)
+}
+
+// CollectorFactory creates a collector for a domain.
+type CollectorFactory func(domain *Domain) Collector
+
+type collectorEntry struct {
+	name    string
+	factory CollectorFactory
+}
+
+// Registered collectors by weblogic version, in registration order.
+var collectorRegistry = map[string][]collectorEntry{}
+
+// RegisterCollector adds a collector factory for a weblogic version and a
+// metric family. It panics if the family is already registered for that
+// version, as that can only happen because of a programming error.
+func RegisterCollector(version string, name string, factory CollectorFactory) {
+	for _, entry := range collectorRegistry[version] {
+		if entry.name == name {
+			panic(fmt.Sprintf("collector %s already registered for weblogic %s", name, version))
+		}
+	}
+	collectorRegistry[version] = append(collectorRegistry[version], collectorEntry{name: name, factory: factory})
+}
+
+// NewCollectors creates the collectors enabled in the domain configuration for
+// a weblogic version, by name. The version may be a full domain version like 12.2.1.3.0,
+// it is matched against the registered versions by prefix. Versions without a
+// registry of their own use the 12.2 collectors.
+func NewCollectors(domain *Domain, version string) map[string]Collector {
+	registered := registeredVersion(version)
+	if registered == "" {
+		logp.Warn("No collectors for weblogic version %s, using %s", version, WlsVersion122)
+		registered = WlsVersion122
+	}
+	entries := collectorRegistry[registered]
+
+	for _, name := range domain.config.Collectors {
+		if !collectorRegistered(entries, name) {
+			logp.Warn("Collector %s not available for weblogic version %s", name, version)
+		}
+	}
+
+	collectors := map[string]Collector{}
+	for _, entry := range entries {
+		if !stringInSlice(entry.name, domain.config.Collectors) {
+			continue
+		}
+		collectors[entry.name] = entry.factory(domain)
+		logp.Info("Collector %s enabled for domain %s, weblogic %s", entry.name, domain.config.Name, version)
+	}
+	return collectors
+}
+
+// CheckCollectors returns an error if a collector name is not registered for
+// any weblogic version.
+func CheckCollectors(names []string) error {
+	for _, name := range names {
+		found := false
+		for _, entries := range collectorRegistry {
+			if collectorRegistered(entries, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Unknown collector %s", name)
+		}
+	}
+	return nil
+}
+
+// registeredVersion returns the longest registered version that is the
+// given version or a dotted prefix of it.
+func registeredVersion(version string) string {
+	match := ""
+	for registered := range collectorRegistry {
+		if version != registered && !strings.HasPrefix(version, registered+".") {
+			continue
+		}
+		if len(registered) > len(match) {
+			match = registered
+		}
+	}
+	return match
+}
+
+func collectorRegistered(entries []collectorEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrKindInternal is the err_kind of the errors not caused by a request, like
+// a collector panic.
+const ErrKindInternal = "internal"
+
+// errorEvent creates the event published when a resource can not be
+// collected. Request errors add their kind, status code and response body.
+func errorEvent(serverName string, metricType string, err error) beat.Event {
+	fields := common.MapStr{
+		"err_server":       serverName,
+		"err_metric_type":  metricType,
+		"err_metric_error": fmt.Sprintf("%v", err),
+		"err_kind":         ErrKindInternal,
+		"err_metric_body":  "",
+	}
+	if request_err, ok := err.(*RequestError); ok {
+		fields["err_kind"] = request_err.Kind
+		fields["err_metric_body"] = request_err.Body
+		if request_err.StatusCode != 0 {
+			fields["err_status_code"] = request_err.StatusCode
+		}
+	}
+
+	logp.Info("Error : %v", err)
+	return beat.Event{
+		Timestamp: time.Now(),
+		Fields:    fields,
+	}
+}
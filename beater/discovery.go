@@ -0,0 +1,135 @@
+package beater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/logp"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+// Inventory is the set of servers, datasources, applications and JMS servers
+// monitored by the collectors. Everything but the servers is listed per
+// server.
+type Inventory struct {
+	ServerNames  []string
+	Datasources  map[string][]string
+	Applications map[string][]string
+	JMSServers   map[string][]string
+}
+
+// StaticInventory creates the inventory from the servers, datasources,
+// applications and JMS servers listed in the configuration.
+func StaticInventory(cfg config.DomainConfig) *Inventory {
+	inv := &Inventory{
+		ServerNames:  cfg.ServerNames,
+		Datasources:  map[string][]string{},
+		Applications: map[string][]string{},
+		JMSServers:   map[string][]string{},
+	}
+	for _, server_name := range cfg.ServerNames {
+		inv.Datasources[server_name] = cfg.Datasources
+		inv.Applications[server_name] = cfg.Applications
+		inv.JMSServers[server_name] = cfg.JMSServers
+	}
+	return inv
+}
+
+// AllDatasources returns the names of the datasources of all servers.
+func (inv *Inventory) AllDatasources() []string {
+	return unionNames(inv.ServerNames, inv.Datasources)
+}
+
+// AllApplications returns the names of the applications of all servers.
+func (inv *Inventory) AllApplications() []string {
+	return unionNames(inv.ServerNames, inv.Applications)
+}
+
+// AllJMSServers returns the names of the JMS servers of all servers.
+func (inv *Inventory) AllJMSServers() []string {
+	return unionNames(inv.ServerNames, inv.JMSServers)
+}
+
+// Filter removes the servers, datasources, applications and JMS servers not
+// selected by the discovery filters.
+func (inv *Inventory) Filter(cfg config.Discovery) *Inventory {
+	filtered := &Inventory{
+		ServerNames:  []string{},
+		Datasources:  map[string][]string{},
+		Applications: map[string][]string{},
+		JMSServers:   map[string][]string{},
+	}
+	for _, server_name := range inv.ServerNames {
+		if !cfg.Servers.Match(server_name) {
+			continue
+		}
+		filtered.ServerNames = append(filtered.ServerNames, server_name)
+		filtered.Datasources[server_name] = filterNames(inv.Datasources[server_name], cfg.Datasources)
+		filtered.Applications[server_name] = filterNames(inv.Applications[server_name], cfg.Applications)
+		filtered.JMSServers[server_name] = filterNames(inv.JMSServers[server_name], cfg.JMSServers)
+	}
+	return filtered
+}
+
+func (inv *Inventory) String() string {
+	return fmt.Sprintf("servers %v, datasources %v, applications %v, jms servers %v", inv.ServerNames, inv.AllDatasources(), inv.AllApplications(), inv.AllJMSServers())
+}
+
+// Discoverer enumerates the servers, datasources, applications and JMS
+// servers of a weblogic domain.
+type Discoverer interface {
+	Discover(ctx context.Context) (*Inventory, error)
+}
+
+// DiscovererFactory creates a discoverer for a domain.
+type DiscovererFactory func(domain *Domain) Discoverer
+
+var discovererRegistry = map[string]DiscovererFactory{}
+
+// RegisterDiscoverer sets the discoverer factory of a weblogic version.
+func RegisterDiscoverer(version string, factory DiscovererFactory) {
+	if _, found := discovererRegistry[version]; found {
+		panic(fmt.Sprintf("discoverer already registered for weblogic %s", version))
+	}
+	discovererRegistry[version] = factory
+}
+
+// NewDiscoverer creates the discoverer of a weblogic version, matched the same
+// way as the collectors.
+func NewDiscoverer(domain *Domain, version string) Discoverer {
+	registered := registeredVersion(version)
+	if registered == "" {
+		registered = WlsVersion122
+	}
+	factory, found := discovererRegistry[registered]
+	if !found {
+		logp.Warn("No discovery for weblogic version %s", version)
+		return nil
+	}
+	return factory(domain)
+}
+
+func filterNames(names []string, filter config.Filter) []string {
+	filtered := []string{}
+	for _, name := range names {
+		if filter.Match(name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
+
+func unionNames(serverNames []string, byServer map[string][]string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, server_name := range serverNames {
+		for _, name := range byServer[server_name] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,404 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+// Domain polls one weblogic domain with its own collectors, inventory and
+// connection to the publisher pipeline.
+type Domain struct {
+	config     config.DomainConfig
+	client     beat.Client
+	pool       *Pool
+	rest       *RestClient
+	tests      *PoolTests
+	threads    *ThreadTracker
+	collectors map[string]Collector
+	stop       chan struct{}
+	ctx        context.Context
+	discoverer Discoverer
+	discovered time.Time
+
+	// Shared with the collector schedules
+	mutex     sync.Mutex
+	version   string
+	reconnect bool
+	inventory *Inventory
+	noSearch  bool
+
+	// Servers answering that they have no cluster runtime, not asked again
+	// until the next refresh of the inventory
+	unclustered map[string]bool
+}
+
+// NewDomain creates the domain described by the configuration, polled on
+// the given pool with at most maxRequests requests at the same time.
+func NewDomain(cfg config.DomainConfig, pool *Pool, maxRequests int) (*Domain, error) {
+	if err := CheckCollectors(cfg.Collectors); err != nil {
+		return nil, err
+	}
+
+	rest, err := NewRestClient(cfg, maxRequests)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := &Domain{
+		config:    cfg,
+		pool:      pool,
+		rest:      rest,
+		tests:     NewPoolTests(cfg.TestPool),
+		threads:   NewThreadTracker(),
+		inventory: StaticInventory(cfg),
+	}
+	domain.rest.OnOpen = domain.unreachable
+	return domain, nil
+}
+
+// Connect opens the connection of the domain to the publisher pipeline.
+func (domain *Domain) Connect(pipeline beat.Pipeline) error {
+	client, err := pipeline.Connect()
+	if err != nil {
+		return err
+	}
+	domain.client = client
+	return nil
+}
+
+// Run detects the version and refreshes the inventory of the domain every
+// period, while every collector runs on its own schedule, until ctx is done.
+// Cancelling ctx also abandons the requests in progress.
+func (domain *Domain) Run(ctx context.Context) {
+	domain.ctx = ctx
+	logp.Info("Monitoring domain %s every %v", domain.config.Name, domain.config.Period)
+
+	ticker := time.NewTicker(domain.config.Period)
+	defer ticker.Stop()
+	defer domain.stopCollectors()
+
+	for {
+		if domain.collectors == nil || domain.reconnecting() {
+			if err := domain.loadCollectors(); err != nil {
+				logp.Err("Error detecting weblogic version of domain %s: %v", domain.config.Name, err)
+			}
+		}
+
+		if time.Since(domain.discovered) >= domain.config.Discovery.Refresh {
+			domain.refresh()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startCollectors starts a schedule for every collector with the period
+// configured for it. In bulk mode the collectors able to read a search result
+// share one schedule for every period.
+func (domain *Domain) startCollectors() {
+	domain.stop = make(chan struct{})
+	bulk := map[time.Duration][]string{}
+	for name, collector := range domain.collectors {
+		period := domain.config.CollectorPeriod(name)
+		if _, ok := collector.(BulkCollector); ok && domain.config.Bulk {
+			bulk[period] = append(bulk[period], name)
+			continue
+		}
+		logp.Info("Collector %s of domain %s runs every %v", name, domain.config.Name, period)
+		go domain.schedule(name, collector, period, domain.stop)
+	}
+	for period, names := range bulk {
+		collector := newBulkCollector(domain, names)
+		name := strings.Join(names, ",")
+		logp.Info("Collectors %s of domain %s run every %v with a single search", name, domain.config.Name, period)
+		go domain.schedule(name, collector, period, domain.stop)
+	}
+}
+
+// stopCollectors stops the schedules of the collectors.
+func (domain *Domain) stopCollectors() {
+	if domain.stop != nil {
+		close(domain.stop)
+		domain.stop = nil
+	}
+}
+
+// schedule runs a collector every period until stop is closed. A tick is
+// skipped when the previous cycle of the collector is still running.
+func (domain *Domain) schedule(name string, collector Collector, period time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	running := int32(0)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !atomic.CompareAndSwapInt32(&running, 0, 1) {
+			logp.Warn("Collector %s of domain %s still running, tick skipped", name, domain.config.Name)
+			continue
+		}
+		go func() {
+			defer atomic.StoreInt32(&running, 0)
+			domain.collect(name, collector, period)
+		}()
+	}
+}
+
+// collect runs one cycle of a collector and publishes its events, followed by
+// an event with the duration of the cycle. The cycle must be done within the
+// period of the collector, or the request timeout when longer, the requests
+// still running are abandoned. No cycle runs while the admin server is
+// unreachable, the errors of the requests rejected by the circuit breaker are
+// not published.
+func (domain *Domain) collect(name string, collector Collector, period time.Duration) {
+	if !domain.rest.Available() {
+		logp.Debug("weblogicbeat", "Domain %s unreachable, collector %s cycle skipped", domain.config.Name, name)
+		return
+	}
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(domain.ctx, domain.cycleDeadline(period))
+	defer cancel()
+
+	cycle := NewCycle(ctx, domain.pool, domain.config.Host)
+	cycle.Collect(name, collector)
+	events := cycle.Wait()
+	if domain.ctx.Err() != nil {
+		return
+	}
+	for _, event := range events {
+		if kind, _ := event.Fields.GetValue("err_kind"); kind == ErrKindCircuitOpen {
+			continue
+		}
+		domain.Publish(event)
+	}
+
+	duration := time.Since(start)
+	domain.Publish(beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_metric_type":    "cycle_status",
+			"cycle_collector":   name,
+			"cycle_duration_ms": duration.Nanoseconds() / int64(time.Millisecond),
+			"cycle_tasks":       cycle.Tasks(),
+		},
+	})
+	logp.Info("Domain %s collector %s cycle of %d requests done in %v", domain.config.Name, name, cycle.Tasks(), duration)
+}
+
+// Close closes the connections of the domain to the publisher pipeline and
+// to the admin server.
+func (domain *Domain) Close() {
+	if domain.client != nil {
+		domain.client.Close()
+	}
+	domain.rest.Close()
+}
+
+// loadCollectors creates the collectors for the configured weblogic version,
+// or for the version reported by the admin server when none is configured.
+func (domain *Domain) loadCollectors() error {
+	version := domain.config.WlsVersion
+	if version == "" {
+		ctx, cancel := context.WithTimeout(domain.ctx, domain.setupDeadline())
+		detected, err := DetectVersion(ctx, domain.rest)
+		cancel()
+		if err != nil {
+			return err
+		}
+		version = detected
+		logp.Info("Detected weblogic version %s for domain %s", version, domain.config.Name)
+	}
+
+	if version != domain.Version() || domain.collectors == nil {
+		domain.stopCollectors()
+
+		domain.mutex.Lock()
+		domain.version = version
+		domain.noSearch = false
+		domain.mutex.Unlock()
+
+		domain.collectors = NewCollectors(domain, version)
+		if domain.config.Discovery.Enabled {
+			domain.discoverer = NewDiscoverer(domain, version)
+		}
+		domain.discovered = time.Time{}
+		domain.startCollectors()
+	}
+
+	domain.mutex.Lock()
+	domain.reconnect = false
+	domain.mutex.Unlock()
+	return nil
+}
+
+// refresh forgets the servers found without a cluster and discovers the
+// inventory again, when the discovery is enabled.
+func (domain *Domain) refresh() {
+	domain.discovered = time.Now()
+
+	domain.mutex.Lock()
+	domain.unclustered = nil
+	domain.mutex.Unlock()
+
+	if domain.discoverer != nil {
+		domain.discover()
+	}
+}
+
+// discover refreshes the inventory from the admin server. The previous
+// inventory is kept when the discovery fails.
+func (domain *Domain) discover() {
+	ctx, cancel := context.WithTimeout(domain.ctx, domain.setupDeadline())
+	defer cancel()
+
+	inv, err := domain.discoverer.Discover(ctx)
+	if err != nil {
+		logp.Err("Error discovering weblogic resources of domain %s: %v", domain.config.Name, err)
+		if request_err, ok := err.(*RequestError); ok && request_err.Unreachable() {
+			domain.connectionLost()
+		}
+		return
+	}
+	inv = inv.Filter(domain.config.Discovery)
+	logp.Info("Discovered for domain %s: %s", domain.config.Name, inv)
+
+	domain.mutex.Lock()
+	domain.inventory = inv
+	domain.mutex.Unlock()
+}
+
+// cycleDeadline is the time a cycle of a collector has to finish, its period
+// but never less than the request timeout, so a period shorter than the
+// timeout does not cut every slow request off.
+func (domain *Domain) cycleDeadline(period time.Duration) time.Duration {
+	if domain.config.Timeout > period {
+		return domain.config.Timeout
+	}
+	return period
+}
+
+// setupDeadline is the time the version detection and the discovery have to
+// finish. They make many requests one after the other, so they get the
+// discovery refresh interval, and never less than the request timeout.
+func (domain *Domain) setupDeadline() time.Duration {
+	if domain.config.Timeout > domain.config.Discovery.Refresh {
+		return domain.config.Timeout
+	}
+	return domain.config.Discovery.Refresh
+}
+
+// Get makes a request outside of the collection cycles, like the ones of the
+// discovery, on a slot of the pool.
+func (domain *Domain) Get(ctx context.Context, path string, response interface{}) ([]string, error) {
+	if !domain.pool.acquire(ctx, domain.config.Host) {
+		return nil, contextError(ctx, path)
+	}
+	defer domain.pool.release(domain.config.Host)
+	return domain.rest.Get(ctx, path, response)
+}
+
+// Inventory returns the servers, datasources and applications to monitor.
+func (domain *Domain) Inventory() *Inventory {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	return domain.inventory
+}
+
+// Version returns the weblogic version of the domain.
+func (domain *Domain) Version() string {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	return domain.version
+}
+
+// bulkSupported returns false once the admin server answered that it has no
+// search endpoint.
+func (domain *Domain) bulkSupported() bool {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	return !domain.noSearch
+}
+
+// disableBulk makes the bulk collectors use a request per resource until the
+// version of the domain changes.
+func (domain *Domain) disableBulk() {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	domain.noSearch = true
+}
+
+// clustered returns false for a server that answered it has no cluster
+// runtime since the last refresh of the inventory.
+func (domain *Domain) clustered(serverName string) bool {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	return !domain.unclustered[serverName]
+}
+
+// setUnclustered skips the cluster runtime of a server until the next refresh
+// of the inventory.
+func (domain *Domain) setUnclustered(serverName string) {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	if domain.unclustered == nil {
+		domain.unclustered = map[string]bool{}
+	}
+	domain.unclustered[serverName] = true
+}
+
+// Publish adds the fields common to every event and sends it to the output.
+func (domain *Domain) Publish(event beat.Event) {
+	event.Fields["wb_domain"] = domain.config.Name
+	event.Fields["wb_version"] = domain.Version()
+	domain.client.Publish(event)
+}
+
+// ErrorEvent creates the error event of a failed request of a collector. A
+// managed server that does not answer does not make the domain reconnect, only
+// the admin server does, when its circuit breaker opens or the discovery
+// fails.
+func (domain *Domain) ErrorEvent(serverName string, metricType string, err error) beat.Event {
+	return errorEvent(serverName, metricType, err)
+}
+
+// unreachable publishes the event of an admin server that stopped answering,
+// once for every time the circuit breaker opens.
+func (domain *Domain) unreachable(err *RequestError) {
+	event := errorEvent("", "domain_unreachable", err)
+	event.Fields["err_cooldown_ms"] = domain.config.Breaker.Cooldown.Nanoseconds() / int64(time.Millisecond)
+	domain.connectionLost()
+	domain.Publish(event)
+}
+
+// connectionLost marks the admin server as unreachable, the version is
+// detected again before the next cycle. It is called from the cycle tasks
+// when the circuit breaker opens.
+func (domain *Domain) connectionLost() {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	domain.reconnect = true
+}
+
+func (domain *Domain) reconnecting() bool {
+	domain.mutex.Lock()
+	defer domain.mutex.Unlock()
+	return domain.reconnect
+}
@@ -0,0 +1,23 @@
+// +build !integration
+
+package beater
+
+import (
+	"testing"
+)
+
+func TestErrorEventKeepsConnection(t *testing.T) {
+	domain := &Domain{}
+	errors := []error{
+		&RequestError{Kind: ErrKindTimeout, URL: "http://admin:7001/management/weblogic/latest/domainRuntime/serverRuntimes/ess_server1"},
+		&RequestError{Kind: ErrKindConnectionRefused},
+		&RequestError{Kind: ErrKindCircuitOpen},
+		&RequestError{Kind: ErrKindServer, StatusCode: 500},
+	}
+	for _, err := range errors {
+		domain.ErrorEvent("ess_server1", "server_status", err)
+		if domain.reconnecting() {
+			t.Fatalf("error %v of a managed server made the domain reconnect", err)
+		}
+	}
+}
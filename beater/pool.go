@@ -0,0 +1,169 @@
+package beater
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Pool bounds the number of requests running at the same time, in total and
+// for every admin host. Every domain has its own pool.
+type Pool struct {
+	workers chan struct{}
+	perHost int
+	mutex   sync.Mutex
+	hosts   map[string]chan struct{}
+}
+
+// NewPool creates a pool running up to workers requests, with at most perHost
+// of them against the same host.
+func NewPool(workers int, perHost int) *Pool {
+	return &Pool{
+		workers: make(chan struct{}, workers),
+		perHost: perHost,
+		hosts:   map[string]chan struct{}{},
+	}
+}
+
+// acquire blocks until the host and the pool have a free slot, or ctx is
+// done. The host slot is taken first so a request waiting for its host does
+// not hold a worker. A slot freed at the same time ctx is done is not taken.
+func (p *Pool) acquire(ctx context.Context, host string) bool {
+	slots := p.host(host)
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return false
+	}
+	select {
+	case p.workers <- struct{}{}:
+	case <-ctx.Done():
+		<-slots
+		return false
+	}
+	if ctx.Err() != nil {
+		p.release(host)
+		return false
+	}
+	return true
+}
+
+func (p *Pool) release(host string) {
+	<-p.workers
+	<-p.host(host)
+}
+
+func (p *Pool) host(host string) chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	slots, found := p.hosts[host]
+	if !found {
+		slots = make(chan struct{}, p.perHost)
+		p.hosts[host] = slots
+	}
+	return slots
+}
+
+// Cycle runs the tasks of one collection cycle of a domain on the pool. The
+// events of the tasks are returned in the order the tasks were added, no
+// matter in which order they finish. Tasks not started when the context of
+// the cycle is done are reported as error events.
+type Cycle struct {
+	ctx     context.Context
+	pool    *Pool
+	host    string
+	wg      sync.WaitGroup
+	results []*[]beat.Event
+}
+
+// NewCycle creates a cycle running its tasks on the pool against host until
+// ctx is done.
+func NewCycle(ctx context.Context, pool *Pool, host string) *Cycle {
+	return &Cycle{
+		ctx:  ctx,
+		pool: pool,
+		host: host,
+	}
+}
+
+// Collect lets a collector add its tasks to the cycle. A collector panic is
+// reported as an error event instead of stopping the beat.
+func (c *Cycle) Collect(name string, collector Collector) {
+	defer func() {
+		if r := recover(); r != nil {
+			logp.Err("Collector %s panic: %v\n%s", name, r, debug.Stack())
+			events := []beat.Event{errorEvent("", name, fmt.Errorf("panic: %v", r))}
+			c.results = append(c.results, &events)
+		}
+	}()
+	collector.Collect(c)
+}
+
+// Go runs task on the pool. Tasks must only read the state shared with other
+// tasks. A task that does not start or panics is reported as an error event
+// of the server and metric type it collects, the other tasks of the cycle go
+// on.
+func (c *Cycle) Go(serverName string, metricType string, task func() []beat.Event) {
+	result := &[]beat.Event{}
+	c.results = append(c.results, result)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if !c.pool.acquire(c.ctx, c.host) {
+			*result = []beat.Event{errorEvent(serverName, metricType, contextError(c.ctx, c.host))}
+			return
+		}
+		defer c.pool.release(c.host)
+		defer func() {
+			if r := recover(); r != nil {
+				logp.Err("Task %s %s panic: %v\n%s", metricType, serverName, r, debug.Stack())
+				*result = []beat.Event{errorEvent(serverName, metricType, fmt.Errorf("panic: %v", r))}
+			}
+		}()
+		*result = task()
+	}()
+}
+
+// Do runs task on the pool and waits for it. It returns false when the cycle
+// is done before the task could start.
+func (c *Cycle) Do(task func()) bool {
+	if !c.pool.acquire(c.ctx, c.host) {
+		return false
+	}
+	defer c.pool.release(c.host)
+	task()
+	return true
+}
+
+// Add adds events that need no request to the cycle, in the order of the
+// tasks.
+func (c *Cycle) Add(events ...beat.Event) {
+	c.results = append(c.results, &events)
+}
+
+// Context returns the context of the requests of the cycle.
+func (c *Cycle) Context() context.Context {
+	return c.ctx
+}
+
+// Wait waits for every task and returns their events in order.
+func (c *Cycle) Wait() []beat.Event {
+	c.wg.Wait()
+
+	events := []beat.Event{}
+	for _, result := range c.results {
+		events = append(events, *result...)
+	}
+	return events
+}
+
+// Tasks returns the number of tasks added to the cycle.
+func (c *Cycle) Tasks() int {
+	return len(c.results)
+}
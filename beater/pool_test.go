@@ -0,0 +1,139 @@
+// +build !integration
+
+package beater
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+func TestSlowDomainDoesNotDelayOthers(t *testing.T) {
+	c := config.DefaultConfig
+	release := make(chan struct{})
+	defer close(release)
+
+	// Three domains whose admin servers hang hold every slot of their host
+	started := make(chan struct{})
+	hung := 0
+	for i := 0; i < 3; i++ {
+		cycle := NewCycle(context.Background(), NewPool(c.Workers, c.MaxRequestsPerHost), fmt.Sprintf("http://hung%d:7001", i))
+		for j := 0; j < c.MaxRequestsPerHost; j++ {
+			cycle.Go("AdminServer", "server_status", func() []beat.Event {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+			hung++
+		}
+	}
+	for i := 0; i < hung; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("%d of %d hung requests started", i, hung)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	healthy := NewCycle(ctx, NewPool(c.Workers, c.MaxRequestsPerHost), "http://healthy:7001")
+	healthy.Go("AdminServer", "server_status", func() []beat.Event {
+		return []beat.Event{{Fields: common.MapStr{"srv_name": "AdminServer"}}}
+	})
+
+	start := time.Now()
+	events := healthy.Wait()
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("healthy domain waited %v for the hung ones", elapsed)
+	}
+	if len(events) != 1 || events[0].Fields["srv_name"] != "AdminServer" {
+		t.Errorf("healthy domain events %v, want its server status", events)
+	}
+}
+
+func TestCycleOrder(t *testing.T) {
+	cycle := NewCycle(context.Background(), NewPool(10, 4), "http://admin:7001")
+
+	// Every task finishes before the ones added earlier
+	delays := []time.Duration{30 * time.Millisecond, 20 * time.Millisecond, 10 * time.Millisecond, 0}
+	for i, delay := range delays {
+		i, delay := i, delay
+		cycle.Go("AdminServer", "server_status", func() []beat.Event {
+			time.Sleep(delay)
+			return []beat.Event{{Fields: common.MapStr{"task": i}}}
+		})
+		if i == 1 {
+			cycle.Add(beat.Event{Fields: common.MapStr{"task": "added"}})
+		}
+	}
+
+	want := []interface{}{0, 1, "added", 2, 3}
+	events := cycle.Wait()
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Fields["task"] != want[i] {
+			t.Errorf("event %d from task %v, want %v", i, event.Fields["task"], want[i])
+		}
+	}
+}
+
+func TestCyclePanic(t *testing.T) {
+	cycle := NewCycle(context.Background(), NewPool(10, 4), "http://admin:7001")
+	cycle.Go("ess_server1", "datasource_status", func() []beat.Event {
+		panic("nil map")
+	})
+	cycle.Go("ess_server1", "server_status", func() []beat.Event {
+		return []beat.Event{{Fields: common.MapStr{"wb_metric_type": "server_status"}}}
+	})
+
+	events := cycle.Wait()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].Fields["err_server"] != "ess_server1" || events[0].Fields["err_metric_type"] != "datasource_status" {
+		t.Errorf("panic event %v, want an error of ess_server1 datasource_status", events[0].Fields)
+	}
+	if events[1].Fields["wb_metric_type"] != "server_status" {
+		t.Errorf("event after the panic %v, want the server status", events[1].Fields)
+	}
+}
+
+func TestCycleDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	cycle := NewCycle(ctx, NewPool(1, 1), "http://admin:7001")
+
+	// The only worker is busy until the deadline, the other task never starts
+	started := make(chan struct{})
+	cycle.Go("AdminServer", "server_status", func() []beat.Event {
+		close(started)
+		<-ctx.Done()
+		return []beat.Event{{Fields: common.MapStr{"wb_metric_type": "server_status"}}}
+	})
+	<-started
+	cycle.Go("ess_server1", "jvm_status", func() []beat.Event {
+		t.Error("task started after the deadline")
+		return nil
+	})
+
+	events := cycle.Wait()
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].Fields["wb_metric_type"] != "server_status" {
+		t.Errorf("first event %v, want the server status", events[0].Fields)
+	}
+	unstarted := events[1].Fields
+	if unstarted["err_server"] != "ess_server1" || unstarted["err_metric_type"] != "jvm_status" || unstarted["err_kind"] != ErrKindDeadline {
+		t.Errorf("unstarted task event %v, want a deadline error of ess_server1 jvm_status", unstarted)
+	}
+}
@@ -0,0 +1,320 @@
+package beater
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common/transport/tlscommon"
+	"github.com/elastic/beats/libbeat/logp"
+
+	"github.com/carlgira/weblogicbeat/config"
+	resty "gopkg.in/resty.v1"
+)
+
+// Kinds of request errors, published as err_kind.
+const (
+	ErrKindDNS               = "dns"
+	ErrKindConnectionRefused = "connection_refused"
+	ErrKindTimeout           = "timeout"
+	ErrKindTLS               = "tls"
+	ErrKindTransport         = "transport"
+	ErrKindUnauthorized      = "unauthorized"
+	ErrKindForbidden         = "forbidden"
+	ErrKindNotFound          = "not_found"
+	ErrKindServer            = "server_error"
+	ErrKindHTTP              = "http_error"
+	ErrKindMalformed         = "malformed_response"
+	ErrKindCircuitOpen       = "circuit_open"
+	ErrKindDeadline          = "cycle_deadline"
+	ErrKindCanceled          = "canceled"
+)
+
+// RequestError is a failed request to the REST api of the admin server.
+type RequestError struct {
+	Kind       string
+	URL        string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s %v", e.Kind, e.URL, e.Err)
+	}
+	return fmt.Sprintf("%s: %s returned %d", e.Kind, e.URL, e.StatusCode)
+}
+
+// Unreachable returns true when the request got no response from the admin
+// server.
+func (e *RequestError) Unreachable() bool {
+	switch e.Kind {
+	case ErrKindDNS, ErrKindConnectionRefused, ErrKindTimeout, ErrKindTLS, ErrKindTransport, ErrKindCircuitOpen:
+		return true
+	}
+	return false
+}
+
+// Retryable returns true when the same request may succeed if repeated.
+func (e *RequestError) Retryable() bool {
+	switch e.Kind {
+	case ErrKindConnectionRefused, ErrKindTimeout, ErrKindTransport, ErrKindServer:
+		return true
+	}
+	return false
+}
+
+// failure returns true when the error counts for the circuit breaker, the
+// admin server did not answer or could not handle the request.
+func (e *RequestError) failure() bool {
+	return e.Kind == ErrKindServer || (e.Unreachable() && e.Kind != ErrKindCircuitOpen)
+}
+
+// RestClient makes the requests to the REST api of a domain admin server,
+// with its own connections, ssl settings, proxy and headers.
+type RestClient struct {
+	config    config.DomainConfig
+	client    *resty.Client
+	transport *http.Transport
+	breaker   *CircuitBreaker
+
+	// OnOpen is called with the last error when the circuit breaker opens.
+	OnOpen func(err *RequestError)
+}
+
+// NewRestClient creates the REST client of a domain, keeping up to maxIdle
+// connections open to the admin server.
+func NewRestClient(cfg config.DomainConfig, maxIdle int) (*RestClient, error) {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.ConnectTimeout,
+			KeepAlive: cfg.KeepAlive,
+		}).DialContext,
+		TLSHandshakeTimeout: cfg.ConnectTimeout,
+		MaxIdleConnsPerHost: maxIdle,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   cfg.KeepAlive <= 0,
+	}
+
+	if cfg.ProxyURL != "" {
+		proxy_url, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Error in proxy_url: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy_url)
+	}
+
+	tls_config, err := tlscommon.LoadTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading ssl settings: %v", err)
+	}
+	if tls_config != nil {
+		transport.TLSClientConfig = tls_config.BuildModuleConfig("")
+	}
+
+	client := resty.New().
+		SetTransport(transport).
+		SetHeader("Accept", "application/json").
+		SetHeader("X-Requested-By", "weblogicbeat").
+		SetHeaders(cfg.Headers).
+		SetBasicAuth(cfg.Username, cfg.Password)
+
+	return &RestClient{
+		config:    cfg,
+		client:    client,
+		transport: transport,
+		breaker:   NewCircuitBreaker(cfg.Breaker),
+	}, nil
+}
+
+// Close closes the idle connections to the admin server.
+func (c *RestClient) Close() {
+	c.transport.CloseIdleConnections()
+}
+
+// Available returns false while the circuit breaker of the admin server is
+// open.
+func (c *RestClient) Available() bool {
+	return c.breaker.Allow()
+}
+
+// Get requests the resource at path and decodes it into response. It returns
+// the missing or mistyped fields of the response, or a *RequestError when the
+// resource could not be read. Failed requests are retried with backoff, the
+// request is abandoned when ctx is done.
+func (c *RestClient) Get(ctx context.Context, path string, response interface{}) ([]string, error) {
+	return c.request(ctx, http.MethodGet, path, nil, response, c.config.Retry.MaxRetries)
+}
+
+// Search posts a query to a search endpoint of the REST api and decodes the
+// result into response. Searches only read resources, they are retried like
+// the GET requests.
+func (c *RestClient) Search(ctx context.Context, path string, query interface{}, response interface{}) ([]string, error) {
+	return c.request(ctx, http.MethodPost, path, query, response, c.config.Retry.MaxRetries)
+}
+
+// Post invokes an operation of the REST api and decodes its result into
+// response. Operations are not retried.
+func (c *RestClient) Post(ctx context.Context, path string, body interface{}, response interface{}) ([]string, error) {
+	return c.request(ctx, http.MethodPost, path, body, response, 0)
+}
+
+func (c *RestClient) request(ctx context.Context, method string, path string, body interface{}, response interface{}, retries int) ([]string, error) {
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.Allow() {
+			return nil, &RequestError{Kind: ErrKindCircuitOpen, URL: c.config.Host + path, Err: fmt.Errorf("admin server unreachable, requests suspended")}
+		}
+
+		field_errors, err := c.execute(ctx, method, path, body, response)
+		if err == nil {
+			if c.breaker.Success() {
+				logp.Info("Admin server of domain %s reachable again", c.config.Name)
+			}
+			return field_errors, nil
+		}
+
+		request_err := err.(*RequestError)
+		if !request_err.failure() {
+			if request_err.StatusCode != 0 {
+				c.breaker.Success()
+			}
+			return nil, err
+		}
+		if !request_err.Retryable() || attempt >= retries {
+			if c.breaker.Failure() {
+				logp.Warn("Admin server of domain %s unreachable, requests suspended for %v", c.config.Name, c.config.Breaker.Cooldown)
+				if c.OnOpen != nil {
+					c.OnOpen(request_err)
+				}
+			}
+			return nil, err
+		}
+
+		wait := c.backoff(attempt)
+		logp.Debug("weblogicbeat", "Retrying %s in %v after %v", path, wait, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, contextError(ctx, c.config.Host+path)
+		}
+	}
+}
+
+// backoff returns a random wait up to the retry backoff doubled for every
+// previous attempt, limited by the maximum backoff.
+func (c *RestClient) backoff(attempt int) time.Duration {
+	limit := c.config.Retry.Backoff
+	for i := 0; i < attempt && limit < c.config.Retry.MaxBackoff; i++ {
+		limit *= 2
+	}
+	if limit > c.config.Retry.MaxBackoff {
+		limit = c.config.Retry.MaxBackoff
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(limit)) + 1)
+}
+
+func (c *RestClient) execute(ctx context.Context, method string, path string, body interface{}, response interface{}) ([]string, error) {
+	resource_url := c.config.Host + path
+
+	request_ctx := ctx
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		request_ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+
+	request := c.client.R().SetContext(request_ctx)
+	if body != nil {
+		request.SetHeader("Content-Type", "application/json").SetBody(body)
+	}
+	resp, err := request.Execute(method, resource_url)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx, resource_url)
+		}
+		return nil, &RequestError{Kind: transportErrorKind(err), URL: resource_url, Err: err}
+	}
+	if resp == nil {
+		return nil, &RequestError{Kind: ErrKindTransport, URL: resource_url, Err: fmt.Errorf("no response")}
+	}
+	if resp.StatusCode() != 200 {
+		return nil, &RequestError{Kind: statusErrorKind(resp.StatusCode()), URL: resource_url, StatusCode: resp.StatusCode(), Body: resp.String()}
+	}
+
+	field_errors, err := decodeResponse(resp.Body(), response)
+	if err != nil {
+		return nil, &RequestError{Kind: ErrKindMalformed, URL: resource_url, StatusCode: resp.StatusCode(), Body: resp.String(), Err: err}
+	}
+	return field_errors, nil
+}
+
+// contextError is the error of a request abandoned because its cycle ran out
+// of time or the beat is stopping.
+func contextError(ctx context.Context, resource_url string) *RequestError {
+	if ctx.Err() == context.DeadlineExceeded {
+		return &RequestError{Kind: ErrKindDeadline, URL: resource_url, Err: ctx.Err()}
+	}
+	return &RequestError{Kind: ErrKindCanceled, URL: resource_url, Err: ctx.Err()}
+}
+
+func statusErrorKind(status int) string {
+	switch {
+	case status == 401:
+		return ErrKindUnauthorized
+	case status == 403:
+		return ErrKindForbidden
+	case status == 404:
+		return ErrKindNotFound
+	case status >= 500:
+		return ErrKindServer
+	}
+	return ErrKindHTTP
+}
+
+func transportErrorKind(err error) string {
+	if url_err, ok := err.(*url.Error); ok {
+		err = url_err.Err
+	}
+
+	if net_err, ok := err.(net.Error); ok && net_err.Timeout() {
+		return ErrKindTimeout
+	}
+
+	switch e := err.(type) {
+	case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError, tls.RecordHeaderError:
+		return ErrKindTLS
+	case *net.OpError:
+		if _, ok := e.Err.(*net.DNSError); ok {
+			return ErrKindDNS
+		}
+		if sys_err, ok := e.Err.(*os.SyscallError); ok && sys_err.Err == syscall.ECONNREFUSED {
+			return ErrKindConnectionRefused
+		}
+	}
+
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "tls:") || strings.Contains(message, "x509:"):
+		return ErrKindTLS
+	case strings.Contains(message, "connection refused"):
+		return ErrKindConnectionRefused
+	case strings.Contains(message, "no such host"):
+		return ErrKindDNS
+	}
+	return ErrKindTransport
+}
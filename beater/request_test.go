@@ -0,0 +1,147 @@
+// +build !integration
+
+package beater
+
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	retry := config.Retry{MaxRetries: 10, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	client := &RestClient{config: config.DomainConfig{Retry: retry}}
+
+	limits := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for attempt, limit := range limits {
+		for i := 0; i < 100; i++ {
+			wait := client.backoff(attempt)
+			if wait <= 0 || wait > limit {
+				t.Fatalf("attempt %d: backoff %v out of (0, %v]", attempt, wait, limit)
+			}
+		}
+	}
+
+	// Large attempts must not overflow
+	if wait := client.backoff(100); wait <= 0 || wait > time.Second {
+		t.Fatalf("attempt 100: backoff %v out of (0, 1s]", wait)
+	}
+
+	client.config.Retry.Backoff = 0
+	if wait := client.backoff(1); wait != 0 {
+		t.Fatalf("backoff without a base wait is %v, want 0", wait)
+	}
+}
+
+func TestStatusErrorKind(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{401, ErrKindUnauthorized},
+		{403, ErrKindForbidden},
+		{404, ErrKindNotFound},
+		{400, ErrKindHTTP},
+		{302, ErrKindHTTP},
+		{500, ErrKindServer},
+		{503, ErrKindServer},
+	}
+	for _, test := range tests {
+		if got := statusErrorKind(test.status); got != test.want {
+			t.Errorf("status %d: got %s, want %s", test.status, got, test.want)
+		}
+	}
+}
+
+func TestTransportErrorKind(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}
+	no_host := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "admin"}}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"connection refused", refused, ErrKindConnectionRefused},
+		{"connection refused in url error", &url.Error{Op: "Get", URL: "http://admin", Err: refused}, ErrKindConnectionRefused},
+		{"unknown host", &url.Error{Op: "Get", URL: "http://admin", Err: no_host}, ErrKindDNS},
+		{"dns timeout", &net.DNSError{Err: "timeout", Name: "admin", IsTimeout: true}, ErrKindTimeout},
+		{"unknown authority", x509.UnknownAuthorityError{}, ErrKindTLS},
+		{"tls message", errors.New("remote error: tls: handshake failure"), ErrKindTLS},
+		{"refused message", errors.New("dial tcp: connection refused"), ErrKindConnectionRefused},
+		{"other", errors.New("EOF"), ErrKindTransport},
+	}
+	for _, test := range tests {
+		if got := transportErrorKind(test.err); got != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRequestErrorUnreachable(t *testing.T) {
+	tests := []struct {
+		kind        string
+		unreachable bool
+	}{
+		{ErrKindDNS, true},
+		{ErrKindConnectionRefused, true},
+		{ErrKindTimeout, true},
+		{ErrKindTLS, true},
+		{ErrKindTransport, true},
+		{ErrKindCircuitOpen, true},
+		{ErrKindServer, false},
+		{ErrKindUnauthorized, false},
+		{ErrKindNotFound, false},
+		{ErrKindHTTP, false},
+		{ErrKindMalformed, false},
+		{ErrKindDeadline, false},
+		{ErrKindCanceled, false},
+	}
+	for _, test := range tests {
+		err := &RequestError{Kind: test.kind}
+		if got := err.Unreachable(); got != test.unreachable {
+			t.Errorf("%s: unreachable %v, want %v", test.kind, got, test.unreachable)
+		}
+	}
+}
+
+func TestRequestErrorRetryable(t *testing.T) {
+	tests := []struct {
+		kind      string
+		retryable bool
+	}{
+		{ErrKindDNS, false},
+		{ErrKindConnectionRefused, true},
+		{ErrKindTimeout, true},
+		{ErrKindTLS, false},
+		{ErrKindTransport, true},
+		{ErrKindCircuitOpen, false},
+		{ErrKindServer, true},
+		{ErrKindUnauthorized, false},
+		{ErrKindNotFound, false},
+		{ErrKindHTTP, false},
+		{ErrKindMalformed, false},
+		{ErrKindDeadline, false},
+		{ErrKindCanceled, false},
+	}
+	for _, test := range tests {
+		err := &RequestError{Kind: test.kind}
+		if got := err.Retryable(); got != test.retryable {
+			t.Errorf("%s: retryable %v, want %v", test.kind, got, test.retryable)
+		}
+	}
+}
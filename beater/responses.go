@@ -0,0 +1,933 @@
+package beater
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// States of a decoded response field.
+const (
+	fieldMissing = iota
+	fieldValid
+	fieldMistyped
+)
+
+// Number is a numeric field of a REST response. A missing or mistyped value
+// does not make the decoding fail, it is reported by decodeResponse.
+type Number struct {
+	value float64
+	state int
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Number) UnmarshalJSON(data []byte) error {
+	n.state = decodeField(data, &n.value)
+	return nil
+}
+
+// Value returns the number, or nil when it is missing or mistyped.
+func (n Number) Value() interface{} {
+	if n.state != fieldValid {
+		return nil
+	}
+	return n.value
+}
+
+// MB returns the number divided by 1000000, or nil when it is missing or
+// mistyped.
+func (n Number) MB() interface{} {
+	if n.state != fieldValid {
+		return nil
+	}
+	return int(n.value / 1000000)
+}
+
+// Long returns the number as an integer, or nil when it is missing or
+// mistyped.
+func (n Number) Long() interface{} {
+	if n.state != fieldValid {
+		return nil
+	}
+	return int64(n.value)
+}
+
+func (n Number) fieldState() int {
+	return n.state
+}
+
+// String is a string field of a REST response.
+type String struct {
+	value string
+	state int
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *String) UnmarshalJSON(data []byte) error {
+	s.state = decodeField(data, &s.value)
+	return nil
+}
+
+// Value returns the string, or nil when it is missing or mistyped.
+func (s String) Value() interface{} {
+	if s.state != fieldValid {
+		return nil
+	}
+	return s.value
+}
+
+// String returns the string, empty when it is missing or mistyped.
+func (s String) String() string {
+	return s.value
+}
+
+func (s String) fieldState() int {
+	return s.state
+}
+
+// Bool is a boolean field of a REST response.
+type Bool struct {
+	value bool
+	state int
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	b.state = decodeField(data, &b.value)
+	return nil
+}
+
+// Value returns the boolean, or nil when it is missing or mistyped.
+func (b Bool) Value() interface{} {
+	if b.state != fieldValid {
+		return nil
+	}
+	return b.value
+}
+
+func (b Bool) fieldState() int {
+	return b.state
+}
+
+// List is an array field of a REST response with values of any type.
+type List struct {
+	value []interface{}
+	state int
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *List) UnmarshalJSON(data []byte) error {
+	l.state = decodeField(data, &l.value)
+	return nil
+}
+
+// String formats the values of the list.
+func (l List) String() string {
+	return fmt.Sprintf("%v", l.value)
+}
+
+// Strings returns the values of the list formatted as strings.
+func (l List) Strings() []string {
+	values := []string{}
+	for _, value := range l.value {
+		values = append(values, fmt.Sprintf("%v", value))
+	}
+	return values
+}
+
+func (l List) fieldState() int {
+	return l.state
+}
+
+func decodeField(data []byte, value interface{}) int {
+	if string(data) == "null" {
+		return fieldMissing
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fieldMistyped
+	}
+	return fieldValid
+}
+
+type responseField interface {
+	fieldState() int
+}
+
+var responseFieldType = reflect.TypeOf((*responseField)(nil)).Elem()
+
+// decodeResponse decodes a REST response into one of the response structs.
+// The error is only set when the body is not a json document of the expected
+// shape. Missing or mistyped fields leave the decoding going and are returned
+// as field errors, so they can be published with the event.
+func decodeResponse(body []byte, response interface{}) ([]string, error) {
+	field_errors := []string{}
+
+	if err := json.Unmarshal(body, response); err != nil {
+		type_error, ok := err.(*json.UnmarshalTypeError)
+		if !ok || type_error.Field == "" {
+			return nil, err
+		}
+		field_errors = append(field_errors, fmt.Sprintf("%s: expected %v, got %s", type_error.Field, type_error.Type, type_error.Value))
+	}
+
+	if _, unchecked := response.(uncheckedResponse); unchecked {
+		return field_errors, nil
+	}
+	return append(field_errors, responseErrors(response)...), nil
+}
+
+// uncheckedResponse is a response whose missing fields are not looked for
+// when it is decoded, like a search result, where only the parts asked for by
+// the collectors are set and every collector checks its own part.
+type uncheckedResponse interface {
+	unchecked()
+}
+
+// responseErrors returns the missing or mistyped fields of a decoded response
+// or of a part of it, like one item of a collection.
+func responseErrors(response interface{}) []string {
+	return fieldErrors(reflect.ValueOf(response), "", false)
+}
+
+// fieldErrors walks a response struct. Fields tagged response:"optional" may
+// be missing, like the attributes only some kinds of runtimes have.
+func fieldErrors(value reflect.Value, path string, optional bool) []string {
+	errors := []string{}
+
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return errors
+		}
+		value = value.Elem()
+	}
+
+	if value.Type().Implements(responseFieldType) {
+		switch value.Interface().(responseField).fieldState() {
+		case fieldMissing:
+			if !optional {
+				errors = append(errors, path+": missing")
+			}
+		case fieldMistyped:
+			errors = append(errors, path+": wrong type")
+		}
+		return errors
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Anonymous {
+				errors = append(errors, fieldErrors(value.Field(i), path, optional)...)
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			errors = append(errors, fieldErrors(value.Field(i), name, field.Tag.Get("response") == "optional")...)
+		}
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			errors = append(errors, fieldErrors(value.Index(i), fmt.Sprintf("%s.%d", path, i), false)...)
+		}
+	}
+	return errors
+}
+
+// addFieldErrors adds the field errors of the decoded responses to the event
+// fields.
+func addFieldErrors(fields common.MapStr, field_errors ...[]string) {
+	all := []string{}
+	for _, errors := range field_errors {
+		all = append(all, errors...)
+	}
+	if len(all) > 0 {
+		fields["wb_field_errors"] = all
+	}
+}
+
+// metricNames returns the json names of the fields of a response struct,
+// including the ones of embedded structs, to ask for them in a query.
+func metricNames(metrics interface{}) []string {
+	names := []string{}
+	value_type := reflect.TypeOf(metrics)
+	for i := 0; i < value_type.NumField(); i++ {
+		field := value_type.Field(i)
+		if field.Anonymous {
+			names = append(names, metricNames(reflect.Zero(field.Type).Interface())...)
+			continue
+		}
+		names = append(names, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+	return names
+}
+
+// metricFields returns the Number and Bool fields of a metrics struct, and of
+// its embedded structs, as event fields named prefix followed by their json
+// name. Numbers are published as integers, unless tagged metric:"float".
+// Missing fields tagged response:"optional" are left out, the runtime does not
+// have them.
+func metricFields(prefix string, metrics interface{}) common.MapStr {
+	fields := common.MapStr{}
+	value := reflect.Indirect(reflect.ValueOf(metrics))
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Anonymous {
+			fields.Update(metricFields(prefix, value.Field(i).Interface()))
+			continue
+		}
+		name := prefix + strings.Split(field.Tag.Get("json"), ",")[0]
+		if metric, ok := value.Field(i).Interface().(responseField); ok && metric.fieldState() == fieldMissing && field.Tag.Get("response") == "optional" {
+			continue
+		}
+		switch metric := value.Field(i).Interface().(type) {
+		case Number:
+			if field.Tag.Get("metric") == "float" {
+				fields[name] = metric.Value()
+			} else {
+				fields[name] = metric.Long()
+			}
+		case Bool:
+			fields[name] = metric.Value()
+		}
+	}
+	return fields
+}
+
+// heapFields returns the heap of a server in bytes, with the used heap and its
+// percentage of the maximum heap size. The fields in MB of older versions are
+// only added when mb is set.
+func heapFields(size Number, free Number, max Number, mb bool) common.MapStr {
+	fields := common.MapStr{
+		"srv_heapSizeBytes":    size.Long(),
+		"srv_heapFreeBytes":    free.Long(),
+		"srv_heapSizeMaxBytes": max.Long(),
+	}
+	if size.state == fieldValid && free.state == fieldValid {
+		used := size.value - free.value
+		fields["srv_heapUsedBytes"] = int64(used)
+		if max.state == fieldValid && max.value > 0 {
+			fields["srv_heapUsedPercent"] = used * 100 / max.value
+		}
+	}
+	if mb {
+		fields["srv_heapFreeCurrent"] = free.MB()
+		fields["srv_heapSizeCurrent"] = size.MB()
+		fields["srv_heapSizeMax"] = max.MB()
+	}
+	return fields
+}
+
+// HealthState is the health of a 12.2 runtime.
+type HealthState struct {
+	State    String `json:"state"`
+	Symptoms List   `json:"symptoms"`
+}
+
+// NameList is a 12.2 collection of runtimes listed by name.
+type NameList struct {
+	Items []struct {
+		Name String `json:"name"`
+	} `json:"items"`
+}
+
+// ServerRuntime122 is a 12.2 serverRuntimes/<server> resource.
+type ServerRuntime122 struct {
+	Name        String      `json:"name"`
+	State       String      `json:"state"`
+	HealthState HealthState `json:"healthState"`
+}
+
+// JVMRuntime122 is a 12.2 serverRuntimes/<server>/JVMRuntime resource.
+type JVMRuntime122 struct {
+	HeapSizeCurrent Number `json:"heapSizeCurrent"`
+	HeapFreeCurrent Number `json:"heapFreeCurrent"`
+	HeapFreePercent Number `json:"heapFreePercent"`
+	HeapSizeMax     Number `json:"heapSizeMax"`
+}
+
+// JVMStatus122 is the JVMRuntime resource read by the jvm collector. The
+// processor, memory, garbage collection and thread metrics are only exposed
+// by the JRockitRuntime of JRockit JVMs, which weblogic 12.2 does not
+// support. They are missing on HotSpot and left out of the events.
+type JVMStatus122 struct {
+	JVMRuntime122
+	Uptime                      Number `json:"uptime"`
+	JavaVendor                  String `json:"javaVendor"`
+	JavaVersion                 String `json:"javaVersion"`
+	JavaVMVendor                String `json:"javaVMVendor"`
+	OSName                      String `json:"OSName"`
+	OSVersion                   String `json:"OSVersion"`
+	NumberOfProcessors          Number `json:"numberOfProcessors" response:"optional"`
+	JvmProcessorLoad            Number `json:"jvmProcessorLoad" response:"optional" metric:"float"`
+	AllProcessorsAverageLoad    Number `json:"allProcessorsAverageLoad" response:"optional" metric:"float"`
+	TotalGarbageCollectionCount Number `json:"totalGarbageCollectionCount" response:"optional"`
+	TotalGarbageCollectionTime  Number `json:"totalGarbageCollectionTime" response:"optional"`
+	TotalPhysicalMemory         Number `json:"totalPhysicalMemory" response:"optional"`
+	UsedPhysicalMemory          Number `json:"usedPhysicalMemory" response:"optional"`
+	FreePhysicalMemory          Number `json:"freePhysicalMemory" response:"optional"`
+	TotalNumberOfThreads        Number `json:"totalNumberOfThreads" response:"optional"`
+	NumberOfDaemonThreads       Number `json:"numberOfDaemonThreads" response:"optional"`
+}
+
+// DatasourceMetrics are the counters of a JDBCDataSourceRuntimeMBean, the same
+// in 12.1.2 and 12.2. They are published as ds_<json name>.
+type DatasourceMetrics struct {
+	ActiveConnectionsCurrentCount    Number `json:"activeConnectionsCurrentCount"`
+	ActiveConnectionsAverageCount    Number `json:"activeConnectionsAverageCount"`
+	ActiveConnectionsHighCount       Number `json:"activeConnectionsHighCount"`
+	ConnectionsTotalCount            Number `json:"connectionsTotalCount"`
+	CurrCapacity                     Number `json:"currCapacity"`
+	CurrCapacityHighCount            Number `json:"currCapacityHighCount"`
+	NumAvailable                     Number `json:"numAvailable"`
+	NumUnavailable                   Number `json:"numUnavailable"`
+	HighestNumAvailable              Number `json:"highestNumAvailable"`
+	WaitingForConnectionCurrentCount Number `json:"waitingForConnectionCurrentCount"`
+	WaitingForConnectionHighCount    Number `json:"waitingForConnectionHighCount"`
+	WaitingForConnectionTotal        Number `json:"waitingForConnectionTotal"`
+	WaitingForConnectionSuccessTotal Number `json:"waitingForConnectionSuccessTotal"`
+	WaitingForConnectionFailureTotal Number `json:"waitingForConnectionFailureTotal"`
+	WaitSecondsHighCount             Number `json:"waitSecondsHighCount"`
+	LeakedConnectionCount            Number `json:"leakedConnectionCount"`
+	ReserveRequestCount              Number `json:"reserveRequestCount"`
+	FailedReserveRequestCount        Number `json:"failedReserveRequestCount"`
+	PrepStmtCacheAccessCount         Number `json:"prepStmtCacheAccessCount"`
+	PrepStmtCacheHitCount            Number `json:"prepStmtCacheHitCount"`
+	PrepStmtCacheMissCount           Number `json:"prepStmtCacheMissCount"`
+	PrepStmtCacheCurrentSize         Number `json:"prepStmtCacheCurrentSize"`
+	ConnectionDelayTime              Number `json:"connectionDelayTime"`
+	FailuresToReconnectCount         Number `json:"failuresToReconnectCount"`
+}
+
+// DatasourceRuntime122 is a 12.2 JDBCDataSourceRuntimeMBeans/<datasource>
+// resource.
+type DatasourceRuntime122 struct {
+	Name    String `json:"name"`
+	State   String `json:"state"`
+	Enabled Bool   `json:"enabled"`
+	DatasourceMetrics
+}
+
+// TestPool122 is the result of the 12.2 testPool operation of a datasource,
+// an error message when the test failed.
+type TestPool122 struct {
+	Return String `json:"return" response:"optional"`
+}
+
+// ApplicationRuntime122 is a 12.2 applicationRuntimes/<application> resource.
+type ApplicationRuntime122 struct {
+	Name        String      `json:"name"`
+	HealthState HealthState `json:"healthState"`
+}
+
+// ComponentRuntimes122 is a 12.2 applicationRuntimes/<application>/componentRuntimes
+// collection.
+type ComponentRuntimes122 struct {
+	Items []ComponentRuntime122 `json:"items"`
+}
+
+// ComponentRuntime122 is a module of a 12.2 application. The session, context
+// root and deployment state fields are only set for web modules, the servlets
+// only in search results.
+type ComponentRuntime122 struct {
+	Name                     String          `json:"name" response:"optional"`
+	Type                     String          `json:"type" response:"optional"`
+	ComponentName            String          `json:"componentName"`
+	Status                   String          `json:"status"`
+	OpenSessionsCurrentCount Number          `json:"openSessionsCurrentCount" response:"optional"`
+	SessionsOpenedTotalCount Number          `json:"sessionsOpenedTotalCount" response:"optional"`
+	OpenSessionsHighCount    Number          `json:"openSessionsHighCount" response:"optional"`
+	ContextRoot              String          `json:"contextRoot" response:"optional"`
+	DeploymentState          Number          `json:"deploymentState" response:"optional"`
+	Servlets                 *Servlets122    `json:"servlets"`
+	EJBRuntimes              *EJBRuntimes122 `json:"EJBRuntimes"`
+}
+
+// Servlets122 is the 12.2 servlets collection of a web module.
+type Servlets122 struct {
+	Items []ServletRuntime122 `json:"items"`
+}
+
+// ServletRuntime122 is a servlet of a 12.2 web module. Execution times are in
+// milliseconds.
+type ServletRuntime122 struct {
+	ServletName          String `json:"servletName"`
+	InvocationTotalCount Number `json:"invocationTotalCount"`
+	ExecutionTimeTotal   Number `json:"executionTimeTotal"`
+	ExecutionTimeAverage Number `json:"executionTimeAverage"`
+	ExecutionTimeHigh    Number `json:"executionTimeHigh"`
+	ExecutionTimeLow     Number `json:"executionTimeLow"`
+	ReloadTotalCount     Number `json:"reloadTotalCount"`
+	PoolMaxCapacity      Number `json:"poolMaxCapacity"`
+}
+
+// EJBRuntimes122 is the 12.2 EJBRuntimes collection of an EJB module.
+type EJBRuntimes122 struct {
+	Items []EJBRuntime122 `json:"items"`
+}
+
+// EJBRuntime122 is a bean of a 12.2 EJB module. Only the runtimes of its type
+// of bean are set.
+type EJBRuntime122 struct {
+	Name               String                    `json:"name"`
+	Type               String                    `json:"type"`
+	EJBName            String                    `json:"EJBName"`
+	PoolRuntime        *EJBPoolRuntime122        `json:"poolRuntime"`
+	CacheRuntime       *EJBCacheRuntime122       `json:"cacheRuntime"`
+	TransactionRuntime *EJBTransactionRuntime122 `json:"transactionRuntime"`
+	LockingRuntime     *EJBLockingRuntime122     `json:"lockingRuntime"`
+	MDBRuntime122
+}
+
+// MDBRuntime122 are the fields of a 12.2 message driven bean, missing for the
+// other types of bean.
+type MDBRuntime122 struct {
+	MDBStatus             String       `json:"MDBStatus" response:"optional"`
+	ConnectionStatus      String       `json:"connectionStatus" response:"optional"`
+	Suspended             Bool         `json:"suspended" response:"optional"`
+	JMSConnectionAlive    Bool         `json:"JMSConnectionAlive" response:"optional"`
+	ProcessedMessageCount Number       `json:"processedMessageCount" response:"optional"`
+	HealthState           *HealthState `json:"healthState"`
+}
+
+// EJBPoolRuntime122 is the free pool of a stateless, entity or message driven
+// bean.
+type EJBPoolRuntime122 struct {
+	BeansInUseCurrentCount  Number `json:"beansInUseCurrentCount"`
+	PooledBeansCurrentCount Number `json:"pooledBeansCurrentCount"`
+	WaiterCurrentCount      Number `json:"waiterCurrentCount"`
+	TimeoutTotalCount       Number `json:"timeoutTotalCount"`
+	AccessTotalCount        Number `json:"accessTotalCount"`
+	MissTotalCount          Number `json:"missTotalCount"`
+	DestroyedTotalCount     Number `json:"destroyedTotalCount"`
+}
+
+// EJBCacheRuntime122 is the cache of a stateful or entity bean.
+type EJBCacheRuntime122 struct {
+	CachedBeansCurrentCount Number `json:"cachedBeansCurrentCount"`
+	CacheAccessCount        Number `json:"cacheAccessCount"`
+	CacheHitCount           Number `json:"cacheHitCount"`
+	CacheMissCount          Number `json:"cacheMissCount"`
+	ActivationCount         Number `json:"activationCount"`
+	PassivationCount        Number `json:"passivationCount"`
+}
+
+// EJBTransactionRuntime122 are the transactions of a bean.
+type EJBTransactionRuntime122 struct {
+	TransactionsCommittedTotalCount  Number `json:"transactionsCommittedTotalCount"`
+	TransactionsRolledBackTotalCount Number `json:"transactionsRolledBackTotalCount"`
+	TransactionsTimedOutTotalCount   Number `json:"transactionsTimedOutTotalCount"`
+}
+
+// EJBLockingRuntime122 is the lock manager of a stateful or entity bean.
+type EJBLockingRuntime122 struct {
+	LockEntriesCurrentCount Number `json:"lockEntriesCurrentCount"`
+	LockManagerAccessCount  Number `json:"lockManagerAccessCount"`
+	WaiterCurrentCount      Number `json:"waiterCurrentCount"`
+	WaiterTotalCount        Number `json:"waiterTotalCount"`
+	TimeoutTotalCount       Number `json:"timeoutTotalCount"`
+}
+
+// ConnectorPoolRuntime122 is a 12.2 connection pool of a resource adapter, in
+// serverRuntimes/<server>/connectorServiceRuntime/connectionPools.
+type ConnectorPoolRuntime122 struct {
+	Name                           String `json:"name"`
+	PoolName                       String `json:"poolName"`
+	JNDIName                       String `json:"JNDIName"`
+	State                          String `json:"state"`
+	EISType                        String `json:"EISType" response:"optional"`
+	MaxCapacity                    Number `json:"maxCapacity"`
+	InitialCapacity                Number `json:"initialCapacity"`
+	CurrentCapacity                Number `json:"currentCapacity"`
+	ActiveConnectionsCurrentCount  Number `json:"activeConnectionsCurrentCount"`
+	ActiveConnectionsHighCount     Number `json:"activeConnectionsHighCount"`
+	FreeConnectionsCurrentCount    Number `json:"freeConnectionsCurrentCount"`
+	FreeConnectionsHighCount       Number `json:"freeConnectionsHighCount"`
+	NumWaitersCurrentCount         Number `json:"numWaitersCurrentCount"`
+	HighestNumWaiters              Number `json:"highestNumWaiters"`
+	NumUnavailableCurrentCount     Number `json:"numUnavailableCurrentCount"`
+	NumberDetectedLeaks            Number `json:"numberDetectedLeaks"`
+	NumberDetectedIdle             Number `json:"numberDetectedIdle"`
+	ConnectionsCreatedTotalCount   Number `json:"connectionsCreatedTotalCount"`
+	ConnectionsDestroyedTotalCount Number `json:"connectionsDestroyedTotalCount"`
+	ConnectionsRejectedTotalCount  Number `json:"connectionsRejectedTotalCount"`
+	RecycledTotal                  Number `json:"recycledTotal"`
+}
+
+// ConnectorPools122 is the 12.2 connectionPools collection of the connector
+// service of a server.
+type ConnectorPools122 struct {
+	Items []ConnectorPoolRuntime122 `json:"items"`
+}
+
+// ThreadPoolRuntime122 is a 12.2 serverRuntimes/<server>/threadPoolRuntime
+// resource.
+type ThreadPoolRuntime122 struct {
+	OverloadRejectedRequestsCount Number      `json:"overloadRejectedRequestsCount"`
+	PendingUserRequestCount       Number      `json:"pendingUserRequestCount"`
+	ExecuteThreadTotalCount       Number      `json:"executeThreadTotalCount"`
+	StuckThreadCount              Number      `json:"stuckThreadCount"`
+	Throughput                    Number      `json:"throughput"`
+	HoggingThreadCount            Number      `json:"hoggingThreadCount"`
+	HealthState                   HealthState `json:"healthState"`
+}
+
+// JMSMetrics are the message counters shared by JMS servers and
+// destinations.
+type JMSMetrics struct {
+	MessagesCurrentCount  Number `json:"messagesCurrentCount"`
+	MessagesPendingCount  Number `json:"messagesPendingCount"`
+	MessagesHighCount     Number `json:"messagesHighCount"`
+	MessagesReceivedCount Number `json:"messagesReceivedCount"`
+	BytesCurrentCount     Number `json:"bytesCurrentCount"`
+	BytesPendingCount     Number `json:"bytesPendingCount"`
+	BytesHighCount        Number `json:"bytesHighCount"`
+	BytesReceivedCount    Number `json:"bytesReceivedCount"`
+}
+
+// JMSPausedStates are the paused states of JMS servers and destinations.
+type JMSPausedStates struct {
+	ProductionPaused  Bool `json:"productionPaused"`
+	ConsumptionPaused Bool `json:"consumptionPaused"`
+	InsertionPaused   Bool `json:"insertionPaused"`
+}
+
+// JMSServerRuntime122 is a 12.2 JMSRuntime/JMSServers/<server> resource.
+type JMSServerRuntime122 struct {
+	Name                     String      `json:"name"`
+	HealthState              HealthState `json:"healthState"`
+	DestinationsCurrentCount Number      `json:"destinationsCurrentCount"`
+	JMSPausedStates
+	JMSMetrics
+}
+
+// JMSDestinationRuntime122 is a 12.2 destination of a JMS server.
+type JMSDestinationRuntime122 struct {
+	Name                  String `json:"name"`
+	DestinationType       String `json:"destinationType"`
+	ConsumersCurrentCount Number `json:"consumersCurrentCount"`
+	ConsumersHighCount    Number `json:"consumersHighCount"`
+	ConsumersTotalCount   Number `json:"consumersTotalCount"`
+	JMSPausedStates
+	JMSMetrics
+}
+
+// JMSDestinations122 is the 12.2 destinations collection of a JMS server.
+type JMSDestinations122 struct {
+	Items []JMSDestinationRuntime122 `json:"items"`
+}
+
+// JTARuntime122 is a 12.2 serverRuntimes/<server>/JTARuntime resource.
+type JTARuntime122 struct {
+	HealthState                             HealthState `json:"healthState"`
+	TransactionTotalCount                   Number      `json:"transactionTotalCount"`
+	TransactionCommittedTotalCount          Number      `json:"transactionCommittedTotalCount"`
+	TransactionRolledBackTotalCount         Number      `json:"transactionRolledBackTotalCount"`
+	TransactionRolledBackAppTotalCount      Number      `json:"transactionRolledBackAppTotalCount"`
+	TransactionRolledBackResourceTotalCount Number      `json:"transactionRolledBackResourceTotalCount"`
+	TransactionRolledBackTimeoutTotalCount  Number      `json:"transactionRolledBackTimeoutTotalCount"`
+	TransactionRolledBackSystemTotalCount   Number      `json:"transactionRolledBackSystemTotalCount"`
+	TransactionHeuristicsTotalCount         Number      `json:"transactionHeuristicsTotalCount"`
+	TransactionAbandonedTotalCount          Number      `json:"transactionAbandonedTotalCount"`
+	ActiveTransactionsTotalCount            Number      `json:"activeTransactionsTotalCount"`
+	SecondsActiveTotalCount                 Number      `json:"secondsActiveTotalCount"`
+}
+
+// WorkManagerRuntime122 is a 12.2 work manager runtime of a server or of an
+// application.
+type WorkManagerRuntime122 struct {
+	Name              String      `json:"name"`
+	ApplicationName   String      `json:"applicationName" response:"optional"`
+	ModuleName        String      `json:"moduleName" response:"optional"`
+	HealthState       HealthState `json:"healthState"`
+	PendingRequests   Number      `json:"pendingRequests"`
+	CompletedRequests Number      `json:"completedRequests"`
+	StuckThreadCount  Number      `json:"stuckThreadCount"`
+}
+
+// MinThreadsConstraintRuntime122 is a 12.2 minimum threads constraint
+// runtime.
+type MinThreadsConstraintRuntime122 struct {
+	Name                     String `json:"name"`
+	ExecutingRequests        Number `json:"executingRequests"`
+	PendingRequests          Number `json:"pendingRequests"`
+	CompletedRequests        Number `json:"completedRequests"`
+	OutOfOrderExecutionCount Number `json:"outOfOrderExecutionCount"`
+	MustRunCount             Number `json:"mustRunCount"`
+	MaxWaitTime              Number `json:"maxWaitTime"`
+	CurrentWaitTime          Number `json:"currentWaitTime"`
+}
+
+// MaxThreadsConstraintRuntime122 is a 12.2 maximum threads constraint
+// runtime.
+type MaxThreadsConstraintRuntime122 struct {
+	Name              String `json:"name"`
+	ExecutingRequests Number `json:"executingRequests"`
+	DeferredRequests  Number `json:"deferredRequests"`
+}
+
+// RequestClassRuntime122 is a 12.2 request class runtime.
+type RequestClassRuntime122 struct {
+	Name                 String `json:"name"`
+	RequestClassType     String `json:"requestClassType"`
+	CompletedCount       Number `json:"completedCount"`
+	PendingRequestCount  Number `json:"pendingRequestCount"`
+	TotalThreadUse       Number `json:"totalThreadUse"`
+	VirtualTimeIncrement Number `json:"virtualTimeIncrement"`
+}
+
+// WorkManagerSet122 are the work manager, constraint and request class
+// runtimes of a server or of an application.
+type WorkManagerSet122 struct {
+	WorkManagerRuntimes struct {
+		Items []WorkManagerRuntime122 `json:"items"`
+	} `json:"workManagerRuntimes"`
+	MinThreadsConstraintRuntimes struct {
+		Items []MinThreadsConstraintRuntime122 `json:"items"`
+	} `json:"minThreadsConstraintRuntimes"`
+	MaxThreadsConstraintRuntimes struct {
+		Items []MaxThreadsConstraintRuntime122 `json:"items"`
+	} `json:"maxThreadsConstraintRuntimes"`
+	RequestClassRuntimes struct {
+		Items []RequestClassRuntime122 `json:"items"`
+	} `json:"requestClassRuntimes"`
+}
+
+// ExecuteThread122 is a 12.2 execute thread of a thread pool runtime.
+type ExecuteThread122 struct {
+	Name                    String `json:"name"`
+	WorkManagerName         String `json:"workManagerName" response:"optional"`
+	ApplicationName         String `json:"applicationName" response:"optional"`
+	ModuleName              String `json:"moduleName" response:"optional"`
+	CurrentRequest          String `json:"currentRequest" response:"optional"`
+	CurrentRequestStartTime Number `json:"currentRequestStartTime" response:"optional"`
+	User                    String `json:"user" response:"optional"`
+	Transaction             String `json:"transaction" response:"optional"`
+	Stuck                   Bool   `json:"stuck"`
+	Hogger                  Bool   `json:"hogger"`
+}
+
+// ExecuteThreads122 is the list of execute threads of a 12.2
+// threadPoolRuntime resource.
+type ExecuteThreads122 struct {
+	ExecuteThreads []ExecuteThread122 `json:"executeThreads"`
+}
+
+// ThreadDump122 is the thread dump of a 12.2 JVMRuntime resource.
+type ThreadDump122 struct {
+	ThreadStackDump String `json:"threadStackDump"`
+}
+
+// ClusterRuntime122 is a 12.2 serverRuntimes/<server>/clusterRuntime
+// resource, missing when the server is not in a cluster.
+type ClusterRuntime122 struct {
+	Name                         String `json:"name"`
+	ServerNames                  List   `json:"serverNames"`
+	SecondaryDistributionNames   List   `json:"secondaryDistributionNames"`
+	AliveServerCount             Number `json:"aliveServerCount"`
+	ResendRequestsCount          Number `json:"resendRequestsCount"`
+	FragmentsSentCount           Number `json:"fragmentsSentCount"`
+	FragmentsReceivedCount       Number `json:"fragmentsReceivedCount"`
+	ForeignFragmentsDroppedCount Number `json:"foreignFragmentsDroppedCount"`
+	MulticastMessagesLostCount   Number `json:"multicastMessagesLostCount"`
+	PrimaryCount                 Number `json:"primaryCount"`
+	SecondaryCount               Number `json:"secondaryCount"`
+}
+
+// UnicastMessaging122 is the unicastMessaging child of a 12.2 clusterRuntime
+// resource, missing when the cluster uses multicast messaging.
+type UnicastMessaging122 struct {
+	LocalGroupLeaderName        String `json:"localGroupLeaderName" response:"optional"`
+	RemoteGroupsDiscoveredCount Number `json:"remoteGroupsDiscoveredCount"`
+	TotalGroupsCount            Number `json:"totalGroupsCount"`
+}
+
+// ClusterStatus122 is the cluster runtime of a server with its unicast
+// messaging.
+type ClusterStatus122 struct {
+	ClusterRuntime122
+	UnicastMessaging *UnicastMessaging122 `json:"unicastMessaging"`
+}
+
+// ServerConfigs122 is the 12.2 domainConfig/servers collection with the
+// cluster of every server, a reference like ["clusters", "<name>"].
+type ServerConfigs122 struct {
+	Items []struct {
+		Name    String `json:"name"`
+		Cluster List   `json:"cluster" response:"optional"`
+	} `json:"items"`
+}
+
+// ServerLifeCycles122 is the 12.2 domainRuntime/serverLifeCycleRuntimes
+// collection, with the state of the servers that are not running too.
+type ServerLifeCycles122 struct {
+	Items []struct {
+		Name  String `json:"name"`
+		State String `json:"state"`
+	} `json:"items"`
+}
+
+// ServerRuntimes122 is the 12.2 domainRuntime/serverRuntimes collection.
+type ServerRuntimes122 struct {
+	Items []ServerRuntime122 `json:"items"`
+}
+
+// DomainConfig122 is the 12.2 domainConfig resource.
+type DomainConfig122 struct {
+	Name          String `json:"name"`
+	DomainVersion String `json:"domainVersion"`
+}
+
+// SearchResult122 is the result of a 12.2.1 domainRuntime search. Every
+// collector reads the part of the server runtimes it asked for.
+type SearchResult122 struct {
+	ServerRuntimes struct {
+		Items []ServerSearch122 `json:"items"`
+	} `json:"serverRuntimes"`
+}
+
+// ServerSearch122 is a server runtime of a search result with its children.
+type ServerSearch122 struct {
+	ServerRuntime122
+	JVMRuntime         JVMStatus122         `json:"JVMRuntime"`
+	ThreadPoolRuntime  ThreadPoolRuntime122 `json:"threadPoolRuntime"`
+	JDBCServiceRuntime struct {
+		JDBCDataSourceRuntimeMBeans struct {
+			Items []DatasourceRuntime122 `json:"items"`
+		} `json:"JDBCDataSourceRuntimeMBeans"`
+	} `json:"JDBCServiceRuntime"`
+	ApplicationRuntimes struct {
+		Items []ApplicationSearch122 `json:"items"`
+	} `json:"applicationRuntimes"`
+	JTARuntime              JTARuntime122     `json:"JTARuntime"`
+	ClusterRuntime          *ClusterStatus122 `json:"clusterRuntime"`
+	ConnectorServiceRuntime struct {
+		ConnectionPools ConnectorPools122 `json:"connectionPools"`
+	} `json:"connectorServiceRuntime"`
+	WorkManagerSet122
+	JMSRuntime struct {
+		JMSServers struct {
+			Items []JMSServerSearch122 `json:"items"`
+		} `json:"JMSServers"`
+	} `json:"JMSRuntime"`
+}
+
+// JMSServerSearch122 is a JMS server runtime of a search result with its
+// destinations.
+type JMSServerSearch122 struct {
+	JMSServerRuntime122
+	Destinations JMSDestinations122 `json:"destinations"`
+}
+
+// ApplicationSearch122 is an application runtime of a search result with its
+// component runtimes.
+type ApplicationSearch122 struct {
+	ApplicationRuntime122
+	ComponentRuntimes ComponentRuntimes122 `json:"componentRuntimes"`
+	WorkManagerSet122
+}
+
+func (r *SearchResult122) unchecked() {}
+
+// Server returns the runtime of a server, nil when it is not in the result.
+func (r *SearchResult122) Server(name string) *ServerSearch122 {
+	for i := range r.ServerRuntimes.Items {
+		if r.ServerRuntimes.Items[i].Name.String() == name {
+			return &r.ServerRuntimes.Items[i]
+		}
+	}
+	return nil
+}
+
+// Datasource returns the runtime of a datasource of the server, nil when it
+// is not in the result.
+func (s *ServerSearch122) Datasource(name string) *DatasourceRuntime122 {
+	items := s.JDBCServiceRuntime.JDBCDataSourceRuntimeMBeans.Items
+	for i := range items {
+		if items[i].Name.String() == name {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// Application returns the runtime of an application of the server, nil when
+// it is not in the result.
+func (s *ServerSearch122) Application(name string) *ApplicationSearch122 {
+	items := s.ApplicationRuntimes.Items
+	for i := range items {
+		if items[i].Name.String() == name {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// JMSServer returns the runtime of a JMS server of the server, nil when it is
+// not in the result.
+func (s *ServerSearch122) JMSServer(name string) *JMSServerSearch122 {
+	items := s.JMSRuntime.JMSServers.Items
+	for i := range items {
+		if items[i].Name.String() == name {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// NameList1212 is a 12.1.2 tenant-monitoring collection.
+type NameList1212 struct {
+	Body struct {
+		Items []struct {
+			Name String `json:"name"`
+		} `json:"items"`
+	} `json:"body"`
+}
+
+// Server1212 is a 12.1.2 tenant-monitoring/servers/<server> resource.
+type Server1212 struct {
+	Body struct {
+		Item struct {
+			Name            String `json:"name"`
+			State           String `json:"state"`
+			Health          String `json:"health"`
+			HeapFreeCurrent Number `json:"heapFreeCurrent"`
+			HeapSizeCurrent Number `json:"heapSizeCurrent"`
+			HeapSizeMax     Number `json:"heapSizeMax"`
+		} `json:"item"`
+	} `json:"body"`
+}
+
+// Datasource1212 is a 12.1.2 tenant-monitoring/datasources/<datasource>
+// resource.
+type Datasource1212 struct {
+	Body struct {
+		Item struct {
+			Instances []struct {
+				Server  String `json:"server"`
+				State   String `json:"state"`
+				Enabled Bool   `json:"enabled"`
+				DatasourceMetrics
+			} `json:"instances"`
+		} `json:"item"`
+	} `json:"body"`
+}
+
+// Application1212 is a 12.1.2 tenant-monitoring/applications/<application>
+// resource.
+type Application1212 struct {
+	Body struct {
+		Item struct {
+			Name   String `json:"name"`
+			State  String `json:"state"`
+			Health String `json:"health"`
+		} `json:"item"`
+	} `json:"body"`
+}
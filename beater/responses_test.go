@@ -0,0 +1,174 @@
+// +build !integration
+
+package beater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func number(value float64) Number {
+	return Number{value: value, state: fieldValid}
+}
+
+func TestHeapFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		size, free, max Number
+		mb              bool
+		want            common.MapStr
+	}{
+		{
+			name: "used heap and percent",
+			size: number(512000000), free: number(128000000), max: number(1024000000),
+			want: common.MapStr{
+				"srv_heapSizeBytes":    int64(512000000),
+				"srv_heapFreeBytes":    int64(128000000),
+				"srv_heapSizeMaxBytes": int64(1024000000),
+				"srv_heapUsedBytes":    int64(384000000),
+				"srv_heapUsedPercent":  37.5,
+			},
+		},
+		{
+			name: "no percent without maximum",
+			size: number(512000000), free: number(128000000), max: Number{},
+			want: common.MapStr{
+				"srv_heapSizeBytes":    int64(512000000),
+				"srv_heapFreeBytes":    int64(128000000),
+				"srv_heapSizeMaxBytes": nil,
+				"srv_heapUsedBytes":    int64(384000000),
+			},
+		},
+		{
+			name: "no percent with zero maximum",
+			size: number(512), free: number(128), max: number(0),
+			want: common.MapStr{
+				"srv_heapSizeBytes":    int64(512),
+				"srv_heapFreeBytes":    int64(128),
+				"srv_heapSizeMaxBytes": int64(0),
+				"srv_heapUsedBytes":    int64(384),
+			},
+		},
+		{
+			name: "no used heap without free heap",
+			size: number(512000000), free: Number{state: fieldMistyped}, max: number(1024000000),
+			want: common.MapStr{
+				"srv_heapSizeBytes":    int64(512000000),
+				"srv_heapFreeBytes":    nil,
+				"srv_heapSizeMaxBytes": int64(1024000000),
+			},
+		},
+		{
+			name: "MB rounded down",
+			size: number(512999999), free: number(128500000), max: Number{},
+			mb: true,
+			want: common.MapStr{
+				"srv_heapSizeBytes":    int64(512999999),
+				"srv_heapFreeBytes":    int64(128500000),
+				"srv_heapSizeMaxBytes": nil,
+				"srv_heapUsedBytes":    int64(384499999),
+				"srv_heapSizeCurrent":  512,
+				"srv_heapFreeCurrent":  128,
+				"srv_heapSizeMax":      nil,
+			},
+		},
+	}
+	for _, test := range tests {
+		got := heapFields(test.size, test.free, test.max, test.mb)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		response interface{}
+		fail     bool
+		errors   []string
+	}{
+		{
+			name:     "html body",
+			body:     "<html><body>Error 503--Service Unavailable</body></html>",
+			response: &ServerRuntime122{},
+			fail:     true,
+		},
+		{
+			name:     "null body",
+			body:     "null",
+			response: &ServerRuntime122{},
+			errors:   []string{"name: missing", "state: missing", "healthState.state: missing", "healthState.symptoms: missing"},
+		},
+		{
+			name:     "top level array",
+			body:     `[{"name": "AdminServer"}]`,
+			response: &ServerRuntime122{},
+			fail:     true,
+		},
+		{
+			name: "mistyped nested field",
+			body: `{"items": [
+				{"name": "AdminServer", "state": "RUNNING", "healthState": {"state": "ok", "symptoms": []}},
+				{"name": "ess_server1", "state": 2, "healthState": {"state": "ok", "symptoms": []}}
+			]}`,
+			response: &ServerRuntimes122{},
+			errors:   []string{"items.1.state: wrong type"},
+		},
+		{
+			name:     "mistyped list",
+			body:     `{"items": [{"name": "AdminServer", "cluster": "ess_cluster"}]}`,
+			response: &ServerConfigs122{},
+			errors:   []string{"items.0.cluster: wrong type"},
+		},
+		{
+			name:     "mistyped number",
+			body:     `{"heapSizeCurrent": "512M", "heapFreeCurrent": 128, "heapFreePercent": 25, "heapSizeMax": null}`,
+			response: &JVMRuntime122{},
+			errors:   []string{"heapSizeCurrent: wrong type", "heapSizeMax: missing"},
+		},
+		{
+			name:     "missing optional field",
+			body:     `{"items": [{"name": "AdminServer"}, {"name": "ess_server1", "cluster": ["clusters", "ess_cluster"]}]}`,
+			response: &ServerConfigs122{},
+			errors:   []string{},
+		},
+	}
+	for _, test := range tests {
+		field_errors, err := decodeResponse([]byte(test.body), test.response)
+		if (err != nil) != test.fail {
+			t.Errorf("%s: error %v, want failure %v", test.name, err, test.fail)
+			continue
+		}
+		if !test.fail && !reflect.DeepEqual(field_errors, test.errors) {
+			t.Errorf("%s: field errors %q, want %q", test.name, field_errors, test.errors)
+		}
+	}
+}
+
+func TestMetricFieldsOptional(t *testing.T) {
+	jvm := JVMStatus122{}
+	body := `{"heapSizeCurrent": 512, "heapFreeCurrent": 128, "heapFreePercent": 25, "heapSizeMax": 1024,
+		"uptime": 60000, "javaVendor": "Oracle Corporation", "javaVersion": "1.8.0_181",
+		"javaVMVendor": "Oracle Corporation", "OSName": "Linux", "OSVersion": "4.1.12",
+		"totalGarbageCollectionCount": "many"}`
+	if _, err := decodeResponse([]byte(body), &jvm); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := metricFields("jvm_", &jvm)
+	if fields["jvm_uptime"] != int64(60000) {
+		t.Errorf("jvm_uptime %v, want 60000", fields["jvm_uptime"])
+	}
+	if value, found := fields["jvm_totalGarbageCollectionCount"]; !found || value != nil {
+		t.Errorf("mistyped jvm_totalGarbageCollectionCount %v, want it published empty", value)
+	}
+	for _, name := range []string{"jvm_totalGarbageCollectionTime", "jvm_jvmProcessorLoad", "jvm_totalNumberOfThreads"} {
+		if value, found := fields[name]; found {
+			t.Errorf("missing optional %s published as %v", name, value)
+		}
+	}
+}
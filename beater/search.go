@@ -0,0 +1,168 @@
+package beater
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Path of the domainRuntime search endpoint, available since weblogic 12.2.1.
+const searchPath = "/management/weblogic/latest/domainRuntime/search"
+
+// SearchQuery is a node of the search tree posted to the domainRuntime search
+// endpoint. Collections can be restricted to some names.
+type SearchQuery struct {
+	Links    []string                `json:"links"`
+	Fields   []string                `json:"fields"`
+	Name     []string                `json:"name,omitempty"`
+	Children map[string]*SearchQuery `json:"children,omitempty"`
+}
+
+// NewSearchQuery creates the root of a search tree.
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{Links: []string{}, Fields: []string{}}
+}
+
+// Child returns the child node called name, added if missing, with the
+// fields added to the ones asked by other collectors.
+func (q *SearchQuery) Child(name string, fields ...string) *SearchQuery {
+	if q.Children == nil {
+		q.Children = map[string]*SearchQuery{}
+	}
+	child, found := q.Children[name]
+	if !found {
+		child = NewSearchQuery()
+		q.Children[name] = child
+	}
+	child.Fields = addNames(child.Fields, fields)
+	return child
+}
+
+// Names restricts a collection node to the given names, added to the ones
+// asked by other collectors.
+func (q *SearchQuery) Names(names ...string) *SearchQuery {
+	q.Name = addNames(q.Name, names)
+	return q
+}
+
+func addNames(names []string, added []string) []string {
+	for _, name := range added {
+		if !stringInSlice(name, names) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// BulkCollector is a collector that can also read its metrics from a single
+// domainRuntime search shared with the other collectors of the same period.
+type BulkCollector interface {
+	Collector
+
+	// Search adds the resources of the collector to the search tree.
+	Search(query *SearchQuery, inv *Inventory)
+
+	// CollectSearch adds the events of the collector, read from the search
+	// result, to the cycle.
+	CollectSearch(cycle *Cycle, result *SearchResult122, inv *Inventory)
+}
+
+// bulkCollector runs the bulk collectors of one period with a single search.
+// When the admin server has no search endpoint the collectors make their own
+// requests from then on, when the search fails only for that cycle.
+type bulkCollector struct {
+	domain     *Domain
+	collectors []BulkCollector
+}
+
+// newBulkCollector groups the bulk collectors of the domain called names.
+func newBulkCollector(domain *Domain, names []string) *bulkCollector {
+	sort.Strings(names)
+	bulk := &bulkCollector{domain: domain}
+	for _, name := range names {
+		bulk.collectors = append(bulk.collectors, domain.collectors[name].(BulkCollector))
+	}
+	return bulk
+}
+
+// Collect implements Collector.
+func (b *bulkCollector) Collect(cycle *Cycle) {
+	if !b.domain.bulkSupported() {
+		b.collectEach(cycle)
+		return
+	}
+
+	inv := b.domain.Inventory()
+	query := NewSearchQuery()
+	for _, collector := range b.collectors {
+		collector.Search(query, inv)
+	}
+
+	result := SearchResult122{}
+	var err error
+	started := cycle.Do(func() {
+		_, err = b.domain.rest.Search(cycle.Context(), searchPath, query, &result)
+	})
+	if !started {
+		cycle.Add(b.domain.ErrorEvent("", "bulk_search", contextError(cycle.Context(), searchPath)))
+		return
+	}
+
+	if err != nil {
+		switch searchFallback(err) {
+		case searchDisable:
+			logp.Warn("Domain %s has no search endpoint, using a request per resource: %v", b.domain.config.Name, err)
+			b.domain.disableBulk()
+		case searchSkip:
+			cycle.Add(b.domain.ErrorEvent("", "bulk_search", err))
+			return
+		case searchRetry:
+			logp.Warn("Search of domain %s failed, using a request per resource for this cycle: %v", b.domain.config.Name, err)
+			cycle.Add(b.domain.ErrorEvent("", "bulk_search", err))
+		}
+		b.collectEach(cycle)
+		return
+	}
+
+	for _, collector := range b.collectors {
+		collector.CollectSearch(cycle, &result, inv)
+	}
+}
+
+// Ways to go on after a failed search.
+const (
+	// The admin server can not be reached, the requests per resource would
+	// fail too
+	searchSkip = iota
+	// Use a request per resource for this cycle and search again on the next
+	searchRetry
+	// The admin server has no search endpoint, use a request per resource
+	// from now on
+	searchDisable
+)
+
+// searchFallback returns how the bulk collectors go on after a failed search.
+func searchFallback(err error) int {
+	request_err, ok := err.(*RequestError)
+	switch {
+	case ok && request_err.Kind == ErrKindNotFound:
+		return searchDisable
+	case ok && (request_err.Unreachable() || request_err.Kind == ErrKindDeadline || request_err.Kind == ErrKindCanceled):
+		return searchSkip
+	default:
+		return searchRetry
+	}
+}
+
+func (b *bulkCollector) collectEach(cycle *Cycle) {
+	for _, collector := range b.collectors {
+		collector.Collect(cycle)
+	}
+}
+
+// searchNotFound is the error of a resource missing from a search result,
+// like a server that is not running.
+func searchNotFound(resource string, name string) error {
+	return &RequestError{Kind: ErrKindNotFound, URL: searchPath, Err: fmt.Errorf("%s %s not found", resource, name)}
+}
@@ -0,0 +1,102 @@
+// +build !integration
+
+package beater
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSearchQueryJSON(t *testing.T) {
+	query := NewSearchQuery()
+	query.Child("serverRuntimes", "name", "state").
+		Names("AdminServer").
+		Child("JVMRuntime", "heapSizeCurrent")
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"links":[],"fields":[],"children":{"serverRuntimes":{"links":[],"fields":["name","state"],"name":["AdminServer"],` +
+		`"children":{"JVMRuntime":{"links":[],"fields":["heapSizeCurrent"]}}}}}`
+	if string(body) != want {
+		t.Errorf("got %s\nwant %s", body, want)
+	}
+}
+
+func TestSearchQueryMerge(t *testing.T) {
+	wls := &Weblogic122{}
+	inv := &Inventory{ServerNames: []string{"AdminServer", "ess_server1"}}
+	query := NewSearchQuery()
+	wls.ServerSearch(query, inv)
+	wls.ThreadSearch(query, inv)
+	query.Child("serverRuntimes", "name", "state").Names("ess_server1", "soa_server1")
+
+	servers := query.Children["serverRuntimes"]
+	if len(query.Children) != 1 || servers == nil {
+		t.Fatalf("root children %v, want only serverRuntimes", query.Children)
+	}
+	if want := []string{"name", "state", "healthState"}; !reflect.DeepEqual(servers.Fields, want) {
+		t.Errorf("server fields %v, want %v", servers.Fields, want)
+	}
+	if want := []string{"AdminServer", "ess_server1", "soa_server1"}; !reflect.DeepEqual(servers.Name, want) {
+		t.Errorf("server names %v, want %v", servers.Name, want)
+	}
+	for _, child := range []string{"JVMRuntime", "threadPoolRuntime"} {
+		if servers.Children[child] == nil {
+			t.Errorf("child %s of the servers missing", child)
+		}
+	}
+}
+
+func TestSearchResult(t *testing.T) {
+	body := `{"serverRuntimes": {"items": [
+		{"name": "AdminServer", "state": "RUNNING", "healthState": {"state": "ok", "symptoms": []},
+		 "applicationRuntimes": {"items": [{"name": "ESSAPP"}]}},
+		{"name": "ess_server1", "state": "RUNNING", "healthState": {"state": "ok", "symptoms": []}}
+	]}}`
+	result := SearchResult122{}
+	field_errors, err := decodeResponse([]byte(body), &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(field_errors) != 0 {
+		t.Errorf("field errors %v, the parts not asked for must not be checked", field_errors)
+	}
+	if server := result.Server("AdminServer"); server == nil || server.Application("ESSAPP") == nil {
+		t.Error("ESSAPP of AdminServer not found")
+	}
+	if result.Server("ess_server1").Application("ESSAPP") != nil {
+		t.Error("ESSAPP found on ess_server1")
+	}
+	if result.Server("soa_server1") != nil {
+		t.Error("soa_server1 found")
+	}
+}
+
+func TestSearchFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"no search endpoint", &RequestError{Kind: ErrKindNotFound, StatusCode: 404}, searchDisable},
+		{"server error", &RequestError{Kind: ErrKindServer, StatusCode: 500}, searchRetry},
+		{"unavailable", &RequestError{Kind: ErrKindServer, StatusCode: 503}, searchRetry},
+		{"malformed result", &RequestError{Kind: ErrKindMalformed, StatusCode: 200}, searchRetry},
+		{"bad request", &RequestError{Kind: ErrKindHTTP, StatusCode: 400}, searchRetry},
+		{"timeout", &RequestError{Kind: ErrKindTimeout}, searchSkip},
+		{"connection refused", &RequestError{Kind: ErrKindConnectionRefused}, searchSkip},
+		{"circuit open", &RequestError{Kind: ErrKindCircuitOpen}, searchSkip},
+		{"cycle deadline", &RequestError{Kind: ErrKindDeadline}, searchSkip},
+		{"canceled", &RequestError{Kind: ErrKindCanceled}, searchSkip},
+		{"other error", errors.New("unexpected"), searchRetry},
+	}
+	for _, test := range tests {
+		if got := searchFallback(test.err); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package beater
+
+import (
+	"sync"
+	"time"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+// PoolTests decides when the connection pool of a datasource is tested, at
+// most once every interval for every server and datasource.
+type PoolTests struct {
+	config config.TestPool
+
+	mutex  sync.Mutex
+	tested map[string]time.Time
+}
+
+// NewPoolTests creates the pool test schedule of a domain.
+func NewPoolTests(cfg config.TestPool) *PoolTests {
+	return &PoolTests{
+		config: cfg,
+		tested: map[string]time.Time{},
+	}
+}
+
+// Due returns true when the pool of the datasource of the server has to be
+// tested now, and counts it as tested.
+func (t *PoolTests) Due(server string, datasource string) bool {
+	if !t.config.Enabled {
+		return false
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := server + "/" + datasource
+	if time.Since(t.tested[key]) < t.config.Interval {
+		return false
+	}
+	t.tested[key] = time.Now()
+	return true
+}
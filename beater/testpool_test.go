@@ -0,0 +1,48 @@
+// +build !integration
+
+package beater
+
+import (
+	"testing"
+	"time"
+
+	"github.com/carlgira/weblogicbeat/config"
+)
+
+func TestPoolTestsDisabled(t *testing.T) {
+	tests := NewPoolTests(config.TestPool{Enabled: false, Interval: 0})
+	for i := 0; i < 3; i++ {
+		if tests.Due("ess_server1", "EssDS") {
+			t.Fatalf("disabled pool test due on call %d", i+1)
+		}
+	}
+}
+
+func TestPoolTestsInterval(t *testing.T) {
+	interval := 50 * time.Millisecond
+	tests := NewPoolTests(config.TestPool{Enabled: true, Interval: interval})
+
+	steps := []struct {
+		name       string
+		server     string
+		datasource string
+		want       bool
+	}{
+		{"first run after startup", "ess_server1", "EssDS", true},
+		{"again before the interval", "ess_server1", "EssDS", false},
+		{"other datasource of the server", "ess_server1", "EDNDataSource", true},
+		{"same datasource on another server", "ess_server2", "EssDS", true},
+		{"wait for the interval", "", "", false},
+		{"after the interval", "ess_server1", "EssDS", true},
+		{"again after the new test", "ess_server1", "EssDS", false},
+	}
+	for _, step := range steps {
+		if step.server == "" {
+			time.Sleep(interval + 10*time.Millisecond)
+			continue
+		}
+		if got := tests.Due(step.server, step.datasource); got != step.want {
+			t.Errorf("%s: due %v, want %v", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package beater
+
+import (
+	"sync"
+)
+
+// ThreadTracker remembers the stuck and hogging threads already reported for
+// every server, so a thread busy with the same request is reported once.
+type ThreadTracker struct {
+	mutex    sync.Mutex
+	reported map[string]map[string]bool
+}
+
+// NewThreadTracker creates a tracker with no thread reported.
+func NewThreadTracker() *ThreadTracker {
+	return &ThreadTracker{reported: map[string]map[string]bool{}}
+}
+
+// Update replaces the busy threads of a server with keys and returns the keys
+// not reported before.
+func (t *ThreadTracker) Update(server string, keys []string) []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	previous := t.reported[server]
+	current := map[string]bool{}
+	added := []string{}
+	for _, key := range keys {
+		current[key] = true
+		if !previous[key] {
+			added = append(added, key)
+		}
+	}
+	t.reported[server] = current
+	return added
+}
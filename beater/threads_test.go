@@ -0,0 +1,33 @@
+// +build !integration
+
+package beater
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThreadTracker(t *testing.T) {
+	tracker := NewThreadTracker()
+
+	steps := []struct {
+		name   string
+		server string
+		keys   []string
+		want   []string
+	}{
+		{"first cycle reports every thread", "ess_server1", []string{"'1'|100", "'2'|200"}, []string{"'1'|100", "'2'|200"}},
+		{"same threads are not reported again", "ess_server1", []string{"'2'|200", "'1'|100"}, []string{}},
+		{"thread busy with a new request", "ess_server1", []string{"'1'|100", "'2'|300"}, []string{"'2'|300"}},
+		{"other server has its own threads", "ess_server2", []string{"'1'|100"}, []string{"'1'|100"}},
+		{"threads gone are dropped", "ess_server1", []string{"'1'|100"}, []string{}},
+		{"dropped thread stuck again is reported", "ess_server1", []string{"'1'|100", "'2'|300"}, []string{"'2'|300"}},
+		{"no busy threads", "ess_server1", nil, []string{}},
+		{"threads after a cycle without busy threads", "ess_server1", []string{"'1'|100"}, []string{"'1'|100"}},
+	}
+	for _, step := range steps {
+		if got := tracker.Update(step.server, step.keys); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: got %v, want %v", step.name, got, step.want)
+		}
+	}
+}
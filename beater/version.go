@@ -0,0 +1,36 @@
+package beater
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// DetectVersion asks the admin server for the weblogic version of the domain.
+// Domains exposing the 12.2 REST api report their domainVersion, domains that
+// only answer on the tenant-monitoring api are reported as 12.1.2.
+func DetectVersion(ctx context.Context, rest *RestClient) (string, error) {
+	domain := DomainConfig122{}
+	_, err_domain := rest.Get(ctx, "/management/weblogic/latest/domainConfig?links=none&fields=name,domainVersion", &domain)
+	if request_err, ok := err_domain.(*RequestError); ok && request_err.Unreachable() {
+		return "", err_domain
+	}
+	if err_domain == nil {
+		if domain.DomainVersion.String() != "" {
+			return domain.DomainVersion.String(), nil
+		}
+		logp.Warn("Domain version not found in domainConfig response")
+	}
+
+	tenant := map[string]interface{}{}
+	_, err_tenant := rest.Get(ctx, "/management/tenant-monitoring/servers", &tenant)
+	if err_tenant == nil {
+		return WlsVersion1212, nil
+	}
+	if request_err, ok := err_tenant.(*RequestError); ok && request_err.Unreachable() {
+		return "", err_tenant
+	}
+
+	return "", fmt.Errorf("Unable to detect weblogic version, %v (latest), %v (tenant-monitoring)", err_domain, err_tenant)
+}
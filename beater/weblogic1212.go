@@ -1,155 +1,209 @@
 package beater
 
 import (
-	"fmt"
+	"context"
 	"time"
 
-	gabs "github.com/Jeffail/gabs"
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
 
 	"github.com/carlgira/weblogicbeat/config"
-	resty "gopkg.in/resty.v1"
 )
 
-// Weblogicbeat configuration.
+// Weblogic1212 collects metrics from the weblogic 12.1.2 REST api.
 type Weblogic1212 struct {
-	bt     Weblogicbeat
-	config config.Config
+	domain *Domain
+	config config.DomainConfig
 }
 
-func (wls *Weblogic1212) ServerStatusEvent() {
+func init() {
+	registerWeblogic1212("server", (*Weblogic1212).ServerStatusEvent)
+	registerWeblogic1212("datasource", (*Weblogic1212).DatasourceStatusEvent)
+	registerWeblogic1212("application", (*Weblogic1212).ApplicationStatusEvent)
+	registerWeblogic1212("thread", (*Weblogic1212).ThreadStatusEvent)
 
-	for _, server_name := range wls.config.ServerNames {
-		resp_server_status, err_server_status := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/tenant-monitoring/servers/" + server_name)
+	RegisterDiscoverer(WlsVersion1212, func(domain *Domain) Discoverer {
+		return &Weblogic1212{domain: domain, config: domain.config}
+	})
+}
+
+func registerWeblogic1212(name string, event func(*Weblogic1212, *Cycle)) {
+	RegisterCollector(WlsVersion1212, name, func(domain *Domain) Collector {
+		wls := &Weblogic1212{domain: domain, config: domain.config}
+		return CollectorFunc(func(cycle *Cycle) { event(wls, cycle) })
+	})
+}
+
+func (wls *Weblogic1212) ServerStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "server_status", func() []beat.Event {
+			return wls.serverStatus(cycle.Context(), server_name)
+		})
+	}
+}
+
+func (wls *Weblogic1212) serverStatus(ctx context.Context, server_name string) []beat.Event {
+	server_status := Server1212{}
+	server_errors, err_server_status := wls.domain.rest.Get(ctx, "/management/tenant-monitoring/servers/"+server_name, &server_status)
+	if err_server_status != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "server_status", err_server_status)}
+	}
+	server := server_status.Body.Item
+
+	server_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":      server_name,
+			"wb_metric_type": "server_status",
+			"srv_name":       server.Name.Value(),
+			"srv_state":      server.State.Value(),
+			"srv_health":     server.Health.Value(),
+		},
+	}
+	server_status_event.Fields.Update(heapFields(server.HeapSizeCurrent, server.HeapFreeCurrent, server.HeapSizeMax, wls.config.HeapMB))
+	addFieldErrors(server_status_event.Fields, server_errors)
+	logp.Info("Server status %s - event sent", server_name)
+	return []beat.Event{server_status_event}
+}
+
+func (wls *Weblogic1212) DatasourceStatusEvent(cycle *Cycle) {
 
-		if resp_server_status.StatusCode() != 200 {
-			wls.SendErrorEvent(server_name, "server_status", fmt.Sprintf("%v", err_server_status), fmt.Sprintf("%v", resp_server_status))
+	inv := wls.domain.Inventory()
+	for _, datasource := range inv.AllDatasources() {
+		datasource := datasource
+		cycle.Go(datasource, "datasource_status", func() []beat.Event {
+			return wls.datasourceStatus(cycle.Context(), inv, datasource)
+		})
+	}
+}
+
+func (wls *Weblogic1212) datasourceStatus(ctx context.Context, inv *Inventory, datasource string) []beat.Event {
+	dsinfo := Datasource1212{}
+	if _, error_ds := wls.domain.rest.Get(ctx, "/management/tenant-monitoring/datasources/"+datasource, &dsinfo); error_ds != nil {
+		return []beat.Event{wls.domain.ErrorEvent(datasource, "datasource_status", error_ds)}
+	}
+
+	events := []beat.Event{}
+	for _, ds := range dsinfo.Body.Item.Instances {
+		if !stringInSlice(ds.Server.String(), inv.ServerNames) {
 			continue
 		}
 
-		json_server_status, _ := gabs.ParseJSON([]byte(resp_server_status.String()))
-		server := json_server_status.Path("body.item").Data().(map[string]interface{})
-
-		server_status_event := beat.Event{
+		datasource_status_event := beat.Event{
 			Timestamp: time.Now(),
 			Fields: common.MapStr{
-				"wb_server":           server_name,
-				"wb_metric_type":      "server_status",
-				"srv_name":            server["name"],
-				"srv_state":           server["state"],
-				"srv_heapFreeCurrent": int(server["heapFreeCurrent"].(float64) / 1000000),
-				"srv_heapSizeCurrent": int(server["heapSizeCurrent"].(float64) / 1000000),
-				"srv_heapSizeMax":     int(server["heapSizeMax"].(float64) / 1000000),
-				"srv_health":          server["health"],
+				"wb_server":      ds.Server.Value(),
+				"wb_metric_type": "datasource_status",
+				"ds_server":      ds.Server.Value(),
+				"ds_name":        datasource,
+				"ds_state":       ds.State.Value(),
+				"ds_enabled":     ds.Enabled.Value(),
 			},
 		}
-		wls.bt.client.Publish(server_status_event)
-		logp.Info("Server status %s - event sent", server_name)
+		datasource_status_event.Fields.Update(metricFields("ds_", ds.DatasourceMetrics))
+		addFieldErrors(datasource_status_event.Fields, responseErrors(ds))
+		events = append(events, datasource_status_event)
+		logp.Info("Datasource status %s - event sent", ds.Server.String())
 	}
+	return events
 }
 
-func (wls *Weblogic1212) DatasourceStatusEvent() {
+func (wls *Weblogic1212) ApplicationStatusEvent(cycle *Cycle) {
 
-	for _, datasource := range wls.config.Datasources {
-		resp_ds, error_ds := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/tenant-monitoring/datasources/" + datasource)
+	inv := wls.domain.Inventory()
+	for _, application := range inv.AllApplications() {
+		application := application
+		cycle.Go(application, "application_status", func() []beat.Event {
+			return wls.applicationStatus(cycle.Context(), inv, application)
+		})
+	}
+}
+
+func (wls *Weblogic1212) applicationStatus(ctx context.Context, inv *Inventory, application string) []beat.Event {
+	application_status := Application1212{}
+	app_errors, err_app := wls.domain.rest.Get(ctx, "/management/tenant-monitoring/applications/"+application, &application_status)
+	if err_app != nil {
+		return []beat.Event{wls.domain.ErrorEvent(application, "application_status", err_app)}
+	}
+	appinfo := application_status.Body.Item
 
-		if resp_ds.StatusCode() != 200 {
-			wls.SendErrorEvent(datasource, "datasource_status", fmt.Sprintf("%v", error_ds), fmt.Sprintf("%v", resp_ds))
+	events := []beat.Event{}
+	for _, server_name := range inv.ServerNames {
+		if !stringInSlice(application, inv.Applications[server_name]) {
 			continue
 		}
 
-		json_ds_status, _ := gabs.ParseJSON([]byte(resp_ds.String()))
-		dsinfo, _ := json_ds_status.Path("body.item.instances").Children()
-
-		for _, child := range dsinfo {
-			ds := child.Data().(map[string]interface{})
-
-			if !stringInSlice(ds["server"].(string), wls.config.ServerNames) {
-				continue
-			}
-
-			datasource_status_event := beat.Event{
-				Timestamp: time.Now(),
-				Fields: common.MapStr{
-					"wb_server":                        ds["server"],
-					"wb_metric_type":                   "datasource_status",
-					"ds_server":                        ds["server"],
-					"ds_name":                          datasource,
-					"ds_state":                         ds["state"],
-					"ds_enabled":                       ds["enabled"],
-					"ds_activeConnectionsCurrentCount": ds["activeConnectionsCurrentCount"],
-					"ds_connectionsTotalCount":         ds["connectionsTotalCount"],
-					"ds_activeConnectionsAverageCount": ds["activeConnectionsAverageCount"],
-				},
-			}
-			wls.bt.client.Publish(datasource_status_event)
-			logp.Info("Datasource status %s - event sent", ds["server"])
+		application_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":         server_name,
+				"wb_metric_type":    "application_status",
+				"app_server":        server_name,
+				"app_name":          application,
+				"app_componentName": application,
+				"app_state":         appinfo.State.Value(),
+				"app_health":        appinfo.Health.Value(),
+			},
 		}
+		addFieldErrors(application_status_event.Fields, app_errors)
+		events = append(events, application_status_event)
+		logp.Info("Application status %s - event sent", server_name)
 	}
-
+	return events
 }
 
-func (wls *Weblogic1212) ApplicationStatusEvent() {
+func (wls *Weblogic1212) ThreadStatusEvent(cycle *Cycle) {
+}
 
-	for _, application := range wls.config.Applications {
-		resp_app, err_app := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/tenant-monitoring/applications/" + application)
+// Discover lists the servers, datasources and applications of the domain.
+// The tenant-monitoring api only lists datasources and applications for the
+// whole domain, every server gets the complete lists.
+func (wls *Weblogic1212) Discover(ctx context.Context) (*Inventory, error) {
+	base := "/management/tenant-monitoring"
 
-		if resp_app.StatusCode() != 200 {
-			wls.SendErrorEvent(application, "application_status", fmt.Sprintf("%v", err_app), fmt.Sprintf("%v", resp_app))
-			continue
-		}
+	server_names, err := wls.listNames(ctx, base+"/servers")
+	if err != nil {
+		return nil, err
+	}
+	datasources, err := wls.listNames(ctx, base+"/datasources")
+	if err != nil {
+		return nil, err
+	}
+	applications, err := wls.listNames(ctx, base+"/applications")
+	if err != nil {
+		return nil, err
+	}
 
-		json_application_status, _ := gabs.ParseJSON([]byte(resp_app.String()))
-		appinfo := json_application_status.Path("body.item").Data().(map[string]interface{})
-
-		for _, server_name := range wls.config.ServerNames {
-			application_status_event := beat.Event{
-				Timestamp: time.Now(),
-				Fields: common.MapStr{
-					"wb_server":         server_name,
-					"wb_metric_type":    "application_status",
-					"app_server":        server_name,
-					"app_name":          application,
-					"app_componentName": application,
-					"app_state":         appinfo["state"],
-					"app_health":        appinfo["health"],
-				},
-			}
-			wls.bt.client.Publish(application_status_event)
-			logp.Info("Application status %s - event sent", server_name)
-		}
+	inv := &Inventory{
+		ServerNames:  server_names,
+		Datasources:  map[string][]string{},
+		Applications: map[string][]string{},
+		JMSServers:   map[string][]string{},
+	}
+	for _, server_name := range server_names {
+		inv.Datasources[server_name] = datasources
+		inv.Applications[server_name] = applications
 	}
+	return inv, nil
 }
 
-func (wls *Weblogic1212) ThreadStatusEvent() {
-}
+func (wls *Weblogic1212) listNames(ctx context.Context, path string) ([]string, error) {
+	list := NameList1212{}
+	if _, err_list := wls.domain.Get(ctx, path, &list); err_list != nil {
+		return nil, err_list
+	}
 
-func (wls *Weblogic1212) SendErrorEvent(serverName string, metricType string, err string, body string) {
-	error_event := beat.Event{
-		Timestamp: time.Now(),
-		Fields: common.MapStr{
-			"err_server":       serverName,
-			"err_metric_type":  metricType,
-			"err_metric_error": err,
-			"err_metric_body":  body,
-		},
+	names := []string{}
+	for _, item := range list.Body.Items {
+		if name := item.Name.String(); name != "" {
+			names = append(names, name)
+		}
 	}
-	wls.bt.client.Publish(error_event)
-	logp.Info("Error : %s", err)
+	return names, nil
 }
 
 func stringInSlice(a string, list []string) bool {
@@ -1,233 +1,454 @@
 package beater
 
 import (
-	"fmt"
+	"context"
+	"strings"
 	"time"
 
-	gabs "github.com/Jeffail/gabs"
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
 
 	"github.com/carlgira/weblogicbeat/config"
-	resty "gopkg.in/resty.v1"
 )
 
-// Weblogicbeat configuration.
+// Weblogic122 collects metrics from the weblogic 12.2 REST api.
 type Weblogic122 struct {
-	bt     Weblogicbeat
-	config config.Config
+	domain *Domain
+	config config.DomainConfig
 }
 
-func (wls *Weblogic122) ServerStatusEvent() {
+func init() {
+	registerWeblogic122Search("server", (*Weblogic122).ServerStatusEvent, (*Weblogic122).ServerSearch, (*Weblogic122).ServerSearchEvent)
+	registerWeblogic122Search("datasource", (*Weblogic122).DatasourceStatusEvent, (*Weblogic122).DatasourceSearch, (*Weblogic122).DatasourceSearchEvent)
+	registerWeblogic122Search("application", (*Weblogic122).ApplicationStatusEvent, (*Weblogic122).ApplicationSearch, (*Weblogic122).ApplicationSearchEvent)
+	registerWeblogic122Search("thread", (*Weblogic122).ThreadStatusEvent, (*Weblogic122).ThreadSearch, (*Weblogic122).ThreadSearchEvent)
 
-	for _, server_name := range wls.config.ServerNames {
-		resp_server_status, err_server_status := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "?links=none&fields=name,state,healthState")
+	RegisterDiscoverer(WlsVersion122, func(domain *Domain) Discoverer {
+		return &Weblogic122{domain: domain, config: domain.config}
+	})
+}
 
-		if resp_server_status.StatusCode() != 200 {
-			wls.SendErrorEvent(server_name, "server_status", fmt.Sprintf("%v", err_server_status), fmt.Sprintf("%v", resp_server_status))
-			continue
-		}
+func registerWeblogic122(name string, event func(*Weblogic122, *Cycle)) {
+	RegisterCollector(WlsVersion122, name, func(domain *Domain) Collector {
+		wls := &Weblogic122{domain: domain, config: domain.config}
+		return CollectorFunc(func(cycle *Cycle) { event(wls, cycle) })
+	})
+}
 
-		json_server_status, _ := gabs.ParseJSON([]byte(resp_server_status.String()))
-		server := json_server_status.Data().(map[string]interface{})
-		server_health := server["healthState"].(map[string]interface{})
+// registerWeblogic122Search registers a collector that can also read its
+// metrics from a domainRuntime search in bulk mode.
+func registerWeblogic122Search(name string, event func(*Weblogic122, *Cycle), search func(*Weblogic122, *SearchQuery, *Inventory), searchEvent func(*Weblogic122, *Cycle, *SearchResult122, *Inventory)) {
+	RegisterCollector(WlsVersion122, name, func(domain *Domain) Collector {
+		wls := &Weblogic122{domain: domain, config: domain.config}
+		return &searchCollector122{wls: wls, event: event, search: search, searchEvent: searchEvent}
+	})
+}
 
-		resp_server_jvm, err_server_jvm := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/JVMRuntime?links=none&fields=heapSizeCurrent,heapFreeCurrent,heapFreePercent,heapSizeMax")
+type searchCollector122 struct {
+	wls         *Weblogic122
+	event       func(*Weblogic122, *Cycle)
+	search      func(*Weblogic122, *SearchQuery, *Inventory)
+	searchEvent func(*Weblogic122, *Cycle, *SearchResult122, *Inventory)
+}
 
-		if resp_server_jvm.StatusCode() != 200 {
-			wls.SendErrorEvent(server_name, "server_status", fmt.Sprintf("%v", err_server_jvm), fmt.Sprintf("%v", resp_server_jvm))
-			continue
-		}
+func (c *searchCollector122) Collect(cycle *Cycle) {
+	c.event(c.wls, cycle)
+}
 
-		json_server_jvm, _ := gabs.ParseJSON([]byte(resp_server_jvm.String()))
-		server_jvm := json_server_jvm.Data().(map[string]interface{})
+func (c *searchCollector122) Search(query *SearchQuery, inv *Inventory) {
+	c.search(c.wls, query, inv)
+}
 
-		server_status_event := beat.Event{
-			Timestamp: time.Now(),
-			Fields: common.MapStr{
-				"wb_server":           server_name,
-				"wb_metric_type":      "server_status",
-				"srv_name":            server["name"],
-				"srv_state":           server["state"],
-				"srv_heapFreeCurrent": int(server_jvm["heapFreeCurrent"].(float64) / 1000000),
-				"srv_heapSizeCurrent": int(server_jvm["heapSizeCurrent"].(float64) / 1000000),
-				"srv_heapSizeMax":     int(server_jvm["heapSizeMax"].(float64) / 1000000),
-				"srv_symptoms":        fmt.Sprintf("%v", server_health["symptoms"]),
-				"srv_health":          server_health["state"],
-			},
-		}
-		wls.bt.client.Publish(server_status_event)
-		logp.Info("Server status %s - event sent", server_name)
+func (c *searchCollector122) CollectSearch(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	c.searchEvent(c.wls, cycle, result, inv)
+}
+
+func (wls *Weblogic122) ServerStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "server_status", func() []beat.Event {
+			return wls.serverStatus(cycle.Context(), server_name)
+		})
 	}
 }
 
-func (wls *Weblogic122) DatasourceStatusEvent() {
+func (wls *Weblogic122) serverStatus(ctx context.Context, server_name string) []beat.Event {
+	server := ServerRuntime122{}
+	server_errors, err_server_status := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"?links=none&fields=name,state,healthState", &server)
+	if err_server_status != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "server_status", err_server_status)}
+	}
 
-	for _, server_name := range wls.config.ServerNames {
-		for _, datasource := range wls.config.Datasources {
-			resp_ds, error_ds := resty.R().
-				SetHeader("Accept", "application/json").
-				SetHeader("X-Requested-By", "weblogicbeat").
-				SetBasicAuth(wls.config.Username, wls.config.Password).
-				Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/JDBCServiceRuntime/JDBCDataSourceRuntimeMBeans/" + datasource + "?links=none&fields=activeConnectionsCurrentCount,activeConnectionsAverageCount,connectionsTotalCount,enabled,state,name")
+	server_jvm := JVMRuntime122{}
+	jvm_errors, err_server_jvm := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/JVMRuntime?links=none&fields=heapSizeCurrent,heapFreeCurrent,heapFreePercent,heapSizeMax", &server_jvm)
+	if err_server_jvm != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "server_status", err_server_jvm)}
+	}
 
-			if resp_ds.StatusCode() != 200 {
-				wls.SendErrorEvent(server_name, "datasource_status", fmt.Sprintf("%v", error_ds), fmt.Sprintf("%v", resp_ds))
-				continue
-			}
+	return []beat.Event{wls.serverEvent(server_name, &server, &server_jvm, server_errors, jvm_errors)}
+}
 
-			json_ds_status, _ := gabs.ParseJSON([]byte(resp_ds.String()))
-			dsinfo := json_ds_status.Data().(map[string]interface{})
+func (wls *Weblogic122) ServerSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name", "state", "healthState").Names(inv.ServerNames...)
+	servers.Child("JVMRuntime", "heapSizeCurrent", "heapFreeCurrent", "heapFreePercent", "heapSizeMax")
+}
 
-			_, error_ds_test := resty.R().
-				SetHeader("Accept", "application/json").
-				SetHeader("X-Requested-By", "weblogicbeat").
-				SetBasicAuth(wls.config.Username, wls.config.Password).
-				Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/JDBCServiceRuntime/JDBCDataSourceRuntimeMBeans/" + datasource + "/testPool")
+func (wls *Weblogic122) ServerSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "server_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.serverEvent(server_name, &server.ServerRuntime122, &server.JVMRuntime.JVMRuntime122, responseErrors(&server.ServerRuntime122), responseErrors(&server.JVMRuntime.JVMRuntime122)))
+	}
+}
 
-			dstest_value := error_ds_test == nil
+func (wls *Weblogic122) serverEvent(server_name string, server *ServerRuntime122, server_jvm *JVMRuntime122, field_errors ...[]string) beat.Event {
+	server_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":      server_name,
+			"wb_metric_type": "server_status",
+			"srv_name":       server.Name.Value(),
+			"srv_state":      server.State.Value(),
+			"srv_symptoms":   server.HealthState.Symptoms.String(),
+			"srv_health":     server.HealthState.State.Value(),
+		},
+	}
+	server_status_event.Fields.Update(heapFields(server_jvm.HeapSizeCurrent, server_jvm.HeapFreeCurrent, server_jvm.HeapSizeMax, wls.config.HeapMB))
+	addFieldErrors(server_status_event.Fields, field_errors...)
+	logp.Info("Server status %s - event sent", server_name)
+	return server_status_event
+}
 
-			if error_ds != nil {
-				logp.Info("Error test datasource %s pool: %s", datasource, error_ds_test)
-			}
+func (wls *Weblogic122) DatasourceStatusEvent(cycle *Cycle) {
 
-			datasource_status_event := beat.Event{
-				Timestamp: time.Now(),
-				Fields: common.MapStr{
-					"wb_server":                        server_name,
-					"wb_metric_type":                   "datasource_status",
-					"ds_server":                        server_name,
-					"ds_name":                          datasource,
-					"ds_state":                         dsinfo["state"],
-					"ds_enabled":                       dsinfo["enabled"],
-					"ds_activeConnectionsCurrentCount": dsinfo["activeConnectionsCurrentCount"],
-					"ds_connectionsTotalCount":         dsinfo["connectionsTotalCount"],
-					"ds_activeConnectionsAverageCount": dsinfo["activeConnectionsAverageCount"],
-					"ds_testpool":                      dstest_value,
-				},
-			}
-			wls.bt.client.Publish(datasource_status_event)
-			logp.Info("Datasource status %s - event sent", server_name)
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		for _, datasource := range inv.Datasources[server_name] {
+			server_name, datasource := server_name, datasource
+			cycle.Go(server_name, "datasource_status", func() []beat.Event {
+				return wls.datasourceStatus(cycle.Context(), server_name, datasource)
+			})
 		}
 	}
 }
 
-func (wls *Weblogic122) ApplicationStatusEvent() {
+func (wls *Weblogic122) datasourceStatus(ctx context.Context, server_name string, datasource string) []beat.Event {
+	dsinfo := DatasourceRuntime122{}
+	ds_errors, error_ds := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/JDBCServiceRuntime/JDBCDataSourceRuntimeMBeans/"+datasource+"?links=none&fields=name,state,enabled,"+strings.Join(metricNames(DatasourceMetrics{}), ","), &dsinfo)
+	if error_ds != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "datasource_status", error_ds)}
+	}
 
-	for _, server_name := range wls.config.ServerNames {
-		for _, application := range wls.config.Applications {
-			resp_app, err_app := resty.R().
-				SetHeader("Accept", "application/json").
-				SetHeader("X-Requested-By", "weblogicbeat").
-				SetBasicAuth(wls.config.Username, wls.config.Password).
-				Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/applicationRuntimes/" + application + "?links=none&fields=name,healthState")
+	ds_test := common.MapStr{}
+	if wls.domain.tests.Due(server_name, datasource) {
+		ds_test = wls.testPool(ctx, server_name, datasource)
+	}
+	return []beat.Event{wls.datasourceEvent(server_name, datasource, &dsinfo, ds_test, ds_errors)}
+}
 
-			if resp_app.StatusCode() != 200 {
-				wls.SendErrorEvent(server_name, "application_status", fmt.Sprintf("%v", err_app), fmt.Sprintf("%v", resp_app))
-				continue
-			}
+// testPool invokes the testPool operation of a datasource. The pool test
+// fails when the operation returns an error message.
+func (wls *Weblogic122) testPool(ctx context.Context, server_name string, datasource string) common.MapStr {
+	start := time.Now()
+	ds_test := TestPool122{}
+	_, error_ds_test := wls.domain.rest.Post(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/JDBCServiceRuntime/JDBCDataSourceRuntimeMBeans/"+datasource+"/testPool", map[string]interface{}{}, &ds_test)
+	duration := time.Since(start)
+
+	if error_ds_test != nil {
+		logp.Info("Error test datasource %s pool: %s", datasource, error_ds_test)
+		return common.MapStr{
+			"ds_testpool":         false,
+			"ds_testpool_ms":      duration.Nanoseconds() / int64(time.Millisecond),
+			"ds_testpool_message": error_ds_test.Error(),
+		}
+	}
 
-			json_application_status, _ := gabs.ParseJSON([]byte(resp_app.String()))
-			appinfo := json_application_status.Data().(map[string]interface{})
-			server_health := appinfo["healthState"].(map[string]interface{})
+	message := ds_test.Return.String()
+	if message != "" {
+		logp.Info("Test of datasource %s pool on %s failed: %s", datasource, server_name, message)
+	}
+	return common.MapStr{
+		"ds_testpool":         message == "",
+		"ds_testpool_ms":      duration.Nanoseconds() / int64(time.Millisecond),
+		"ds_testpool_message": message,
+	}
+}
 
-			resp_app_comp, err_app_comp := resty.R().
-				SetHeader("Accept", "application/json").
-				SetHeader("X-Requested-By", "weblogicbeat").
-				SetBasicAuth(wls.config.Username, wls.config.Password).
-				Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/applicationRuntimes/" + application + "/componentRuntimes?fields=openSessionsCurrentCount,sessionsOpenedTotalCount,openSessionsHighCount,applicationIdentifier,status,componentName&links=none")
+func (wls *Weblogic122) DatasourceSearch(query *SearchQuery, inv *Inventory) {
+	datasources := inv.AllDatasources()
+	if len(datasources) == 0 {
+		return
+	}
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("JDBCServiceRuntime").
+		Child("JDBCDataSourceRuntimeMBeans", append([]string{"name", "state", "enabled"}, metricNames(DatasourceMetrics{})...)...).
+		Names(datasources...)
+}
 
-			if resp_app_comp.StatusCode() != 200 {
-				wls.SendErrorEvent(server_name, "application_status", fmt.Sprintf("%v", err_app_comp), fmt.Sprintf("%v", resp_app_comp))
+// DatasourceSearchEvent publishes the datasources read from the search result.
+// The pools due for a test are tested with a request per datasource.
+func (wls *Weblogic122) DatasourceSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		for _, datasource := range inv.Datasources[server_name] {
+			server_name, datasource := server_name, datasource
+			if server == nil || server.Datasource(datasource) == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "datasource_status", searchNotFound("datasource", datasource)))
 				continue
 			}
-
-			json_comp_status, _ := gabs.ParseJSON([]byte(resp_app_comp.String()))
-			items, _ := json_comp_status.S("items").Children()
-
-			for _, child := range items {
-				comp := child.Data().(map[string]interface{})
-
-				application_status_event := beat.Event{
-					Timestamp: time.Now(),
-					Fields: common.MapStr{
-						"wb_server":                    server_name,
-						"wb_metric_type":               "application_status",
-						"app_server":                   server_name,
-						"app_name":                     application,
-						"app_componentName":            comp["componentName"],
-						"app_state":                    comp["status"],
-						"app_health":                   server_health["state"],
-						"app_openSessionsCurrentCount": comp["openSessionsCurrentCount"],
-						"app_sessionsOpenedTotalCount": comp["sessionsOpenedTotalCount"],
-						"app_openSessionsHighCount":    comp["openSessionsHighCount"],
-					},
-				}
-				wls.bt.client.Publish(application_status_event)
-				logp.Info("Application status %s - event sent", server_name)
+			dsinfo := server.Datasource(datasource)
+			if !wls.domain.tests.Due(server_name, datasource) {
+				cycle.Add(wls.datasourceEvent(server_name, datasource, dsinfo, common.MapStr{}, responseErrors(dsinfo)))
+				continue
 			}
+			cycle.Go(server_name, "datasource_status", func() []beat.Event {
+				ds_test := wls.testPool(cycle.Context(), server_name, datasource)
+				return []beat.Event{wls.datasourceEvent(server_name, datasource, dsinfo, ds_test, responseErrors(dsinfo))}
+			})
 		}
 	}
 }
 
-func (wls *Weblogic122) ThreadStatusEvent() {
+// datasourceEvent creates the event of a datasource, with the result of the
+// pool test when it was tested.
+func (wls *Weblogic122) datasourceEvent(server_name string, datasource string, dsinfo *DatasourceRuntime122, ds_test common.MapStr, field_errors ...[]string) beat.Event {
+	datasource_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":      server_name,
+			"wb_metric_type": "datasource_status",
+			"ds_server":      server_name,
+			"ds_name":        datasource,
+			"ds_state":       dsinfo.State.Value(),
+			"ds_enabled":     dsinfo.Enabled.Value(),
+		},
+	}
+	datasource_status_event.Fields.Update(metricFields("ds_", dsinfo.DatasourceMetrics))
+	datasource_status_event.Fields.Update(ds_test)
+	addFieldErrors(datasource_status_event.Fields, field_errors...)
+	logp.Info("Datasource status %s - event sent", server_name)
+	return datasource_status_event
+}
 
-	for _, server_name := range wls.config.ServerNames {
-		resp_thread_status, err_thread_status := resty.R().
-			SetHeader("Accept", "application/json").
-			SetHeader("X-Requested-By", "weblogicbeat").
-			SetBasicAuth(wls.config.Username, wls.config.Password).
-			Get(wls.config.Host + "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/threadPoolRuntime?links=none&fields=overloadRejectedRequestsCount,pendingUserRequestCount,executeThreadTotalCount,healthState,stuckThreadCount,throughput,hoggingThreadCount")
+func (wls *Weblogic122) ApplicationStatusEvent(cycle *Cycle) {
 
-		if resp_thread_status.StatusCode() != 200 {
-			wls.SendErrorEvent(server_name, "thread_status", fmt.Sprintf("%v", err_thread_status), fmt.Sprintf("%v", resp_thread_status))
-			continue
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		for _, application := range inv.Applications[server_name] {
+			server_name, application := server_name, application
+			cycle.Go(server_name, "application_status", func() []beat.Event {
+				return wls.applicationStatus(cycle.Context(), server_name, application)
+			})
 		}
+	}
+}
 
-		json_thread_status, _ := gabs.ParseJSON([]byte(resp_thread_status.String()))
-		threads := json_thread_status.Data().(map[string]interface{})
-		thread_health := threads["healthState"].(map[string]interface{})
+func (wls *Weblogic122) applicationStatus(ctx context.Context, server_name string, application string) []beat.Event {
+	appinfo := ApplicationRuntime122{}
+	app_errors, err_app := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/applicationRuntimes/"+application+"?links=none&fields=name,healthState", &appinfo)
+	if err_app != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "application_status", err_app)}
+	}
+
+	components := ComponentRuntimes122{}
+	if _, err_app_comp := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/applicationRuntimes/"+application+"/componentRuntimes?fields=openSessionsCurrentCount,sessionsOpenedTotalCount,openSessionsHighCount,applicationIdentifier,status,componentName&links=none", &components); err_app_comp != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "application_status", err_app_comp)}
+	}
+
+	return wls.applicationEvents(server_name, application, &appinfo, &components, app_errors)
+}
+
+func (wls *Weblogic122) ApplicationSearch(query *SearchQuery, inv *Inventory) {
+	applications := inv.AllApplications()
+	if len(applications) == 0 {
+		return
+	}
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("applicationRuntimes", "name", "healthState").
+		Names(applications...).
+		Child("componentRuntimes", "openSessionsCurrentCount", "sessionsOpenedTotalCount", "openSessionsHighCount", "applicationIdentifier", "status", "componentName")
+}
+
+func (wls *Weblogic122) ApplicationSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		for _, application := range inv.Applications[server_name] {
+			if server == nil || server.Application(application) == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "application_status", searchNotFound("application", application)))
+				continue
+			}
+			appinfo := server.Application(application)
+			cycle.Add(wls.applicationEvents(server_name, application, &appinfo.ApplicationRuntime122, &appinfo.ComponentRuntimes, responseErrors(&appinfo.ApplicationRuntime122))...)
+		}
+	}
+}
 
-		thread_status_event := beat.Event{
+func (wls *Weblogic122) applicationEvents(server_name string, application string, appinfo *ApplicationRuntime122, components *ComponentRuntimes122, app_errors []string) []beat.Event {
+	events := []beat.Event{}
+	for _, comp := range components.Items {
+		application_status_event := beat.Event{
 			Timestamp: time.Now(),
 			Fields: common.MapStr{
-				"wb_server":                        server_name,
-				"wb_metric_type":                   "thread_status",
-				"th_server":                        server_name,
-				"th_overloadRejectedRequestsCount": threads["overloadRejectedRequestsCount"],
-				"th_pendingUserRequestCount":       threads["pendingUserRequestCount"],
-				"th_executeThreadTotalCount":       threads["executeThreadTotalCount"],
-				"th_stuckThreadCount":              threads["stuckThreadCount"],
-				"th_throughput":                    threads["throughput"],
-				"th_hoggingThreadCount":            threads["hoggingThreadCount"].(float64),
-				"th_state":                         thread_health["state"],
-				"th_symptoms":                      fmt.Sprintf("%v", thread_health["symptoms"]),
+				"wb_server":                    server_name,
+				"wb_metric_type":               "application_status",
+				"app_server":                   server_name,
+				"app_name":                     application,
+				"app_componentName":            comp.ComponentName.Value(),
+				"app_state":                    comp.Status.Value(),
+				"app_health":                   appinfo.HealthState.State.Value(),
+				"app_openSessionsCurrentCount": comp.OpenSessionsCurrentCount.Value(),
+				"app_sessionsOpenedTotalCount": comp.SessionsOpenedTotalCount.Value(),
+				"app_openSessionsHighCount":    comp.OpenSessionsHighCount.Value(),
 			},
 		}
-		wls.bt.client.Publish(thread_status_event)
-		logp.Info("Server status %s - event sent", server_name)
+		addFieldErrors(application_status_event.Fields, app_errors, responseErrors(comp))
+		events = append(events, application_status_event)
+		logp.Info("Application status %s - event sent", server_name)
+	}
+	return events
+}
+
+func (wls *Weblogic122) ThreadStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "thread_status", func() []beat.Event {
+			return wls.threadStatus(cycle.Context(), server_name)
+		})
+	}
+}
+
+func (wls *Weblogic122) threadStatus(ctx context.Context, server_name string) []beat.Event {
+	threads := ThreadPoolRuntime122{}
+	thread_errors, err_thread_status := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/threadPoolRuntime?links=none&fields=overloadRejectedRequestsCount,pendingUserRequestCount,executeThreadTotalCount,healthState,stuckThreadCount,throughput,hoggingThreadCount", &threads)
+	if err_thread_status != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "thread_status", err_thread_status)}
+	}
+
+	events := []beat.Event{wls.threadEvent(server_name, &threads, thread_errors)}
+	if wls.config.ThreadDetails.Enabled {
+		if threadsBusy(&threads) {
+			events = append(events, wls.threadDetails(ctx, server_name)...)
+		} else {
+			wls.domain.threads.Update(server_name, nil)
+		}
+	}
+	return events
+}
+
+func (wls *Weblogic122) ThreadSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("threadPoolRuntime", "overloadRejectedRequestsCount", "pendingUserRequestCount", "executeThreadTotalCount", "healthState", "stuckThreadCount", "throughput", "hoggingThreadCount")
+}
+
+func (wls *Weblogic122) ThreadSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "thread_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.threadEvent(server_name, &server.ThreadPoolRuntime, responseErrors(&server.ThreadPoolRuntime)))
+
+		if !wls.config.ThreadDetails.Enabled {
+			continue
+		}
+		if !threadsBusy(&server.ThreadPoolRuntime) {
+			wls.domain.threads.Update(server_name, nil)
+			continue
+		}
+		server_name := server_name
+		cycle.Go(server_name, "thread_detail", func() []beat.Event {
+			return wls.threadDetails(cycle.Context(), server_name)
+		})
 	}
 }
 
-func (wls *Weblogic122) SendErrorEvent(serverName string, metricType string, err string, body string) {
-	error_event := beat.Event{
+func (wls *Weblogic122) threadEvent(server_name string, threads *ThreadPoolRuntime122, thread_errors []string) beat.Event {
+	thread_status_event := beat.Event{
 		Timestamp: time.Now(),
 		Fields: common.MapStr{
-			"err_server":       serverName,
-			"err_metric_type":  metricType,
-			"err_metric_error": err,
-			"err_metric_body":  body,
+			"wb_server":                        server_name,
+			"wb_metric_type":                   "thread_status",
+			"th_server":                        server_name,
+			"th_overloadRejectedRequestsCount": threads.OverloadRejectedRequestsCount.Value(),
+			"th_pendingUserRequestCount":       threads.PendingUserRequestCount.Value(),
+			"th_executeThreadTotalCount":       threads.ExecuteThreadTotalCount.Value(),
+			"th_stuckThreadCount":              threads.StuckThreadCount.Value(),
+			"th_throughput":                    threads.Throughput.Value(),
+			"th_hoggingThreadCount":            threads.HoggingThreadCount.Value(),
+			"th_state":                         threads.HealthState.State.Value(),
+			"th_symptoms":                      threads.HealthState.Symptoms.String(),
 		},
 	}
-	wls.bt.client.Publish(error_event)
-	logp.Info("Error : %s", err)
+	addFieldErrors(thread_status_event.Fields, thread_errors)
+	logp.Info("Server status %s - event sent", server_name)
+	return thread_status_event
+}
+
+// Discover lists the running servers with their datasources, applications and
+// JMS servers. A server keeps its previous lists of the resources that could
+// not be listed.
+func (wls *Weblogic122) Discover(ctx context.Context) (*Inventory, error) {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes"
+
+	server_names, err := wls.listNames(ctx, base+"?links=none&fields=name")
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{
+		ServerNames:  server_names,
+		Datasources:  map[string][]string{},
+		Applications: map[string][]string{},
+		JMSServers:   map[string][]string{},
+	}
+
+	previous := wls.domain.Inventory()
+	for _, server_name := range server_names {
+		datasources, err_ds := wls.listNames(ctx, base+"/"+server_name+"/JDBCServiceRuntime/JDBCDataSourceRuntimeMBeans?links=none&fields=name")
+		if err_ds != nil {
+			logp.Err("Error discovering datasources of %s, keeping the previous ones: %v", server_name, err_ds)
+			datasources = previous.Datasources[server_name]
+		}
+		inv.Datasources[server_name] = datasources
+
+		applications, err_app := wls.listNames(ctx, base+"/"+server_name+"/applicationRuntimes?links=none&fields=name")
+		if err_app != nil {
+			logp.Err("Error discovering applications of %s, keeping the previous ones: %v", server_name, err_app)
+			applications = previous.Applications[server_name]
+		}
+		inv.Applications[server_name] = applications
+
+		jms_servers, err_jms := wls.listNames(ctx, base+"/"+server_name+"/JMSRuntime/JMSServers?links=none&fields=name")
+		if err_jms != nil {
+			logp.Err("Error discovering JMS servers of %s, keeping the previous ones: %v", server_name, err_jms)
+			jms_servers = previous.JMSServers[server_name]
+		}
+		inv.JMSServers[server_name] = jms_servers
+	}
+
+	return inv, nil
+}
+
+func (wls *Weblogic122) listNames(ctx context.Context, path string) ([]string, error) {
+	list := NameList{}
+	if _, err_list := wls.domain.Get(ctx, path, &list); err_list != nil {
+		return nil, err_list
+	}
+
+	names := []string{}
+	for _, item := range list.Items {
+		if name := item.Name.String(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
 }
@@ -0,0 +1,240 @@
+package beater
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("cluster", (*Weblogic122).ClusterStatusEvent, (*Weblogic122).ClusterSearch, (*Weblogic122).ClusterSearchEvent)
+}
+
+// Severity of the health states, to find the worst one of a cluster.
+var healthSeverity = map[string]int{
+	"ok":         0,
+	"warn":       2,
+	"overloaded": 3,
+	"critical":   4,
+	"failed":     5,
+}
+
+// Severity of a health state weblogic added after these ones, worse than ok
+// but not worse than the known problems.
+const unknownHealthSeverity = 1
+
+func healthRank(health string) int {
+	if severity, found := healthSeverity[health]; found {
+		return severity
+	}
+	return unknownHealthSeverity
+}
+
+// ClusterStatusEvent collects the cluster runtime of every clustered server
+// and a summary of every cluster of the domain.
+func (wls *Weblogic122) ClusterStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "cluster_status", func() []beat.Event {
+			return wls.clusterStatus(cycle.Context(), server_name)
+		})
+	}
+	cycle.Go("", "cluster_summary", func() []beat.Event {
+		return wls.clusterSummary(cycle.Context())
+	})
+}
+
+// clusterStatus reads the cluster runtime of a server. A server that is not
+// in a cluster is not asked again until the next refresh of the inventory.
+func (wls *Weblogic122) clusterStatus(ctx context.Context, server_name string) []beat.Event {
+	if !wls.domain.clustered(server_name) {
+		return nil
+	}
+
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/clusterRuntime"
+
+	cluster := ClusterStatus122{}
+	cluster_errors, err_cluster := wls.domain.rest.Get(ctx, base+"?links=none&fields="+strings.Join(metricNames(ClusterRuntime122{}), ","), &cluster.ClusterRuntime122)
+	if err_cluster != nil {
+		if isNotFound(err_cluster) {
+			logp.Debug("weblogicbeat", "Server %s is not in a cluster", server_name)
+			wls.domain.setUnclustered(server_name)
+			return nil
+		}
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "cluster_status", err_cluster)}
+	}
+
+	events := []beat.Event{}
+	unicast := UnicastMessaging122{}
+	unicast_errors, err_unicast := wls.domain.rest.Get(ctx, base+"/unicastMessaging?links=none&fields="+strings.Join(metricNames(UnicastMessaging122{}), ","), &unicast)
+	if err_unicast == nil {
+		cluster.UnicastMessaging = &unicast
+		cluster_errors = append(cluster_errors, unicast_errors...)
+	} else if !isNotFound(err_unicast) {
+		events = append(events, wls.domain.ErrorEvent(server_name, "cluster_status", err_unicast))
+	}
+
+	return append(events, wls.clusterEvent(server_name, &cluster, cluster_errors))
+}
+
+// isNotFound returns true for the error of a resource the server does not
+// have.
+func isNotFound(err error) bool {
+	request_err, ok := err.(*RequestError)
+	return ok && request_err.Kind == ErrKindNotFound
+}
+
+func (wls *Weblogic122) ClusterSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("clusterRuntime", metricNames(ClusterRuntime122{})...).
+		Child("unicastMessaging", metricNames(UnicastMessaging122{})...)
+}
+
+func (wls *Weblogic122) ClusterSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "cluster_status", searchNotFound("server", server_name)))
+			continue
+		}
+		if server.ClusterRuntime != nil {
+			cycle.Add(wls.clusterEvent(server_name, server.ClusterRuntime, responseErrors(server.ClusterRuntime)))
+		}
+	}
+	cycle.Go("", "cluster_summary", func() []beat.Event {
+		return wls.clusterSummary(cycle.Context())
+	})
+}
+
+// clusterEvent creates the cluster_status event of a server. The unicast
+// fields are only set for clusters with unicast messaging.
+func (wls *Weblogic122) clusterEvent(server_name string, cluster *ClusterStatus122, cluster_errors []string) beat.Event {
+	cluster_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":                     server_name,
+			"wb_metric_type":                "cluster_status",
+			"cl_server":                     server_name,
+			"cl_name":                       cluster.Name.Value(),
+			"cl_serverNames":                cluster.ServerNames.Strings(),
+			"cl_secondaryDistributionNames": cluster.SecondaryDistributionNames.Strings(),
+		},
+	}
+	cluster_status_event.Fields.Update(metricFields("cl_", cluster))
+	if unicast := cluster.UnicastMessaging; unicast != nil {
+		cluster_status_event.Fields["cl_unicastLocalGroupLeaderName"] = unicast.LocalGroupLeaderName.Value()
+		cluster_status_event.Fields["cl_unicastRemoteGroupsDiscoveredCount"] = unicast.RemoteGroupsDiscoveredCount.Long()
+		cluster_status_event.Fields["cl_unicastTotalGroupsCount"] = unicast.TotalGroupsCount.Long()
+	}
+	addFieldErrors(cluster_status_event.Fields, cluster_errors)
+	logp.Info("Cluster status %s - event sent", server_name)
+	return cluster_status_event
+}
+
+// clusterSummary creates a cluster_summary event for every cluster of the
+// domain with the state and health of its configured servers. The servers
+// that are not running have no health.
+func (wls *Weblogic122) clusterSummary(ctx context.Context) []beat.Event {
+	configs := ServerConfigs122{}
+	if _, err_config := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainConfig/servers?links=none&fields=name,cluster", &configs); err_config != nil {
+		return []beat.Event{wls.domain.ErrorEvent("", "cluster_summary", err_config)}
+	}
+
+	lifecycles := ServerLifeCycles122{}
+	if _, err_lifecycle := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverLifeCycleRuntimes?links=none&fields=name,state", &lifecycles); err_lifecycle != nil {
+		return []beat.Event{wls.domain.ErrorEvent("", "cluster_summary", err_lifecycle)}
+	}
+
+	runtimes := ServerRuntimes122{}
+	if _, err_runtimes := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes?links=none&fields=name,healthState", &runtimes); err_runtimes != nil {
+		return []beat.Event{wls.domain.ErrorEvent("", "cluster_summary", err_runtimes)}
+	}
+
+	states := map[string]string{}
+	for _, lifecycle := range lifecycles.Items {
+		states[lifecycle.Name.String()] = lifecycle.State.String()
+	}
+	health := map[string]string{}
+	for _, runtime := range runtimes.Items {
+		health[runtime.Name.String()] = runtime.HealthState.State.String()
+	}
+
+	members := map[string][]string{}
+	for _, server := range configs.Items {
+		// The cluster is a reference like ["clusters", "<name>"]
+		reference := server.Cluster.Strings()
+		if len(reference) == 0 {
+			continue
+		}
+		cluster := reference[len(reference)-1]
+		members[cluster] = append(members[cluster], server.Name.String())
+	}
+
+	clusters := []string{}
+	for cluster := range members {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	events := []beat.Event{}
+	for _, cluster := range clusters {
+		events = append(events, clusterSummaryEvent(cluster, members[cluster], states, health))
+	}
+	logp.Info("Cluster summary %s - event sent", wls.config.Name)
+	return events
+}
+
+func clusterSummaryEvent(cluster string, members []string, states map[string]string, health map[string]string) beat.Event {
+	running := 0
+	not_running := []string{}
+	unhealthy := []string{}
+	worst := ""
+	for _, member := range members {
+		if states[member] == "RUNNING" {
+			running++
+		} else {
+			not_running = append(not_running, member)
+		}
+
+		member_health, found := health[member]
+		if !found {
+			continue
+		}
+		if member_health != "ok" {
+			unhealthy = append(unhealthy, member)
+		}
+		if worst == "" || healthRank(member_health) > healthRank(worst) {
+			worst = member_health
+		}
+	}
+
+	member_states := []string{}
+	for _, member := range members {
+		member_states = append(member_states, member+":"+states[member])
+	}
+
+	cluster_summary_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_metric_type":   "cluster_summary",
+			"cls_name":         cluster,
+			"cls_members":      members,
+			"cls_membersCount": len(members),
+			"cls_runningCount": running,
+			"cls_notRunning":   not_running,
+			"cls_unhealthy":    unhealthy,
+			"cls_states":       member_states,
+		},
+	}
+	if worst != "" {
+		cluster_summary_event.Fields["cls_health"] = worst
+	}
+	return cluster_summary_event
+}
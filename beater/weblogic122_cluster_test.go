@@ -0,0 +1,99 @@
+// +build !integration
+
+package beater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestClusterSummaryEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		states  map[string]string
+		health  map[string]string
+		want    common.MapStr
+	}{
+		{
+			name:    "all running and ok",
+			members: []string{"ess_server1", "ess_server2"},
+			states:  map[string]string{"ess_server1": "RUNNING", "ess_server2": "RUNNING"},
+			health:  map[string]string{"ess_server1": "ok", "ess_server2": "ok"},
+			want: common.MapStr{
+				"cls_runningCount": 2,
+				"cls_notRunning":   []string{},
+				"cls_unhealthy":    []string{},
+				"cls_states":       []string{"ess_server1:RUNNING", "ess_server2:RUNNING"},
+				"cls_health":       "ok",
+			},
+		},
+		{
+			name:    "worst health and servers not running",
+			members: []string{"ess_server1", "ess_server2", "ess_server3", "ess_server4"},
+			states:  map[string]string{"ess_server1": "RUNNING", "ess_server2": "RUNNING", "ess_server3": "RUNNING", "ess_server4": "SHUTDOWN"},
+			health:  map[string]string{"ess_server1": "warn", "ess_server2": "critical", "ess_server3": "ok"},
+			want: common.MapStr{
+				"cls_runningCount": 3,
+				"cls_notRunning":   []string{"ess_server4"},
+				"cls_unhealthy":    []string{"ess_server1", "ess_server2"},
+				"cls_states":       []string{"ess_server1:RUNNING", "ess_server2:RUNNING", "ess_server3:RUNNING", "ess_server4:SHUTDOWN"},
+				"cls_health":       "critical",
+			},
+		},
+		{
+			name:    "no server running has no health",
+			members: []string{"ess_server1", "ess_server2"},
+			states:  map[string]string{"ess_server1": "SHUTDOWN"},
+			health:  map[string]string{},
+			want: common.MapStr{
+				"cls_runningCount": 0,
+				"cls_notRunning":   []string{"ess_server1", "ess_server2"},
+				"cls_unhealthy":    []string{},
+				"cls_states":       []string{"ess_server1:SHUTDOWN", "ess_server2:"},
+			},
+		},
+		{
+			name:    "unknown health is worse than ok",
+			members: []string{"ess_server1", "ess_server2"},
+			states:  map[string]string{"ess_server1": "RUNNING", "ess_server2": "RUNNING"},
+			health:  map[string]string{"ess_server1": "ok", "ess_server2": "degraded"},
+			want: common.MapStr{
+				"cls_runningCount": 2,
+				"cls_notRunning":   []string{},
+				"cls_unhealthy":    []string{"ess_server2"},
+				"cls_states":       []string{"ess_server1:RUNNING", "ess_server2:RUNNING"},
+				"cls_health":       "degraded",
+			},
+		},
+		{
+			name:    "unknown health is better than the known problems",
+			members: []string{"ess_server1", "ess_server2"},
+			states:  map[string]string{"ess_server1": "RUNNING", "ess_server2": "RUNNING"},
+			health:  map[string]string{"ess_server1": "degraded", "ess_server2": "warn"},
+			want: common.MapStr{
+				"cls_runningCount": 2,
+				"cls_notRunning":   []string{},
+				"cls_unhealthy":    []string{"ess_server1", "ess_server2"},
+				"cls_states":       []string{"ess_server1:RUNNING", "ess_server2:RUNNING"},
+				"cls_health":       "warn",
+			},
+		},
+	}
+	for _, test := range tests {
+		fields := clusterSummaryEvent("ess_cluster", test.members, test.states, test.health).Fields
+		if fields["cls_name"] != "ess_cluster" || fields["cls_membersCount"] != len(test.members) {
+			t.Errorf("%s: name %v and %v members, want ess_cluster and %d", test.name, fields["cls_name"], fields["cls_membersCount"], len(test.members))
+		}
+		for name, want := range test.want {
+			if got := fields[name]; !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s is %v, want %v", test.name, name, got, want)
+			}
+		}
+		if _, found := fields["cls_health"]; found != (test.want["cls_health"] != nil) {
+			t.Errorf("%s: cls_health %v, want %v", test.name, fields["cls_health"], test.want["cls_health"])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("connector", (*Weblogic122).ConnectorStatusEvent, (*Weblogic122).ConnectorSearch, (*Weblogic122).ConnectorSearchEvent)
+}
+
+func (wls *Weblogic122) ConnectorStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "jca_status", func() []beat.Event {
+			return wls.connectorStatus(cycle.Context(), server_name)
+		})
+	}
+}
+
+func (wls *Weblogic122) connectorStatus(ctx context.Context, server_name string) []beat.Event {
+	pools := ConnectorPools122{}
+	if _, err_pools := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/connectorServiceRuntime/connectionPools?links=none&fields="+strings.Join(metricNames(ConnectorPoolRuntime122{}), ","), &pools); err_pools != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "jca_status", err_pools)}
+	}
+
+	return wls.connectorEvents(server_name, &pools)
+}
+
+func (wls *Weblogic122) ConnectorSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("connectorServiceRuntime").
+		Child("connectionPools", metricNames(ConnectorPoolRuntime122{})...)
+}
+
+func (wls *Weblogic122) ConnectorSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "jca_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.connectorEvents(server_name, &server.ConnectorServiceRuntime.ConnectionPools)...)
+	}
+}
+
+// connectorEvents creates a jca_status event for every connection pool of the
+// resource adapters of a server.
+func (wls *Weblogic122) connectorEvents(server_name string, pools *ConnectorPools122) []beat.Event {
+	events := []beat.Event{}
+	for _, pool := range pools.Items {
+		jca_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":      server_name,
+				"wb_metric_type": "jca_status",
+				"jca_server":     server_name,
+				"jca_name":       pool.Name.Value(),
+				"jca_poolName":   pool.PoolName.Value(),
+				"jca_JNDIName":   pool.JNDIName.Value(),
+				"jca_state":      pool.State.Value(),
+				"jca_EISType":    pool.EISType.Value(),
+			},
+		}
+		jca_status_event.Fields.Update(metricFields("jca_", pool))
+		addFieldErrors(jca_status_event.Fields, responseErrors(pool))
+		events = append(events, jca_status_event)
+	}
+	logp.Info("Connector status %s - event sent", server_name)
+	return events
+}
@@ -0,0 +1,208 @@
+package beater
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("ejb", (*Weblogic122).EJBStatusEvent, (*Weblogic122).EJBSearch, (*Weblogic122).EJBSearchEvent)
+}
+
+// Fields of the EJB modules and of their beans read by the ejb collector.
+var (
+	ejbModuleFields = []string{"name", "type", "componentName", "status"}
+	ejbFields       = []string{"name", "type", "EJBName"}
+	mdbFields       = metricNames(MDBRuntime122{})
+)
+
+// Runtimes of every type of bean.
+var ejbRuntimes = map[string][]string{
+	"StatelessEJBRuntime":     {"poolRuntime", "transactionRuntime"},
+	"StatefulEJBRuntime":      {"cacheRuntime", "lockingRuntime", "transactionRuntime"},
+	"EntityEJBRuntime":        {"poolRuntime", "cacheRuntime", "lockingRuntime", "transactionRuntime"},
+	"MessageDrivenEJBRuntime": {"poolRuntime", "transactionRuntime"},
+}
+
+func (wls *Weblogic122) EJBStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		for _, application := range inv.Applications[server_name] {
+			server_name, application := server_name, application
+			cycle.Go(server_name, "ejb_status", func() []beat.Event {
+				return wls.ejbStatus(cycle.Context(), server_name, application)
+			})
+		}
+	}
+}
+
+func (wls *Weblogic122) ejbStatus(ctx context.Context, server_name string, application string) []beat.Event {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/applicationRuntimes/" + application + "/componentRuntimes"
+
+	components := ComponentRuntimes122{}
+	if _, err_comp := wls.domain.rest.Get(ctx, base+"?links=none&fields="+strings.Join(ejbModuleFields, ","), &components); err_comp != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "ejb_status", err_comp)}
+	}
+
+	events := []beat.Event{}
+	for i := range components.Items {
+		module := &components.Items[i]
+		if !isEJBModule(module) {
+			continue
+		}
+		module_path := base + "/" + url.PathEscape(module.Name.String()) + "/EJBRuntimes"
+		beans := EJBRuntimes122{}
+		if _, err_ejb := wls.domain.rest.Get(ctx, module_path+"?links=none&fields="+strings.Join(ejbFields, ","), &beans); err_ejb != nil {
+			events = append(events, wls.domain.ErrorEvent(server_name, "ejb_status", err_ejb))
+			continue
+		}
+		for j := range beans.Items {
+			bean := &beans.Items[j]
+			if err_runtimes := wls.ejbBeanRuntimes(ctx, module_path+"/"+url.PathEscape(bean.Name.String()), bean); err_runtimes != nil {
+				events = append(events, wls.domain.ErrorEvent(server_name, "ejb_status", err_runtimes))
+				bean.PoolRuntime, bean.CacheRuntime, bean.TransactionRuntime, bean.LockingRuntime = nil, nil, nil, nil
+			}
+		}
+		module.EJBRuntimes = &beans
+		events = append(events, wls.ejbEvents(server_name, application, module)...)
+	}
+	return events
+}
+
+// ejbBeanRuntimes reads the runtimes of the type of the bean, and the status
+// of message driven beans.
+func (wls *Weblogic122) ejbBeanRuntimes(ctx context.Context, path string, bean *EJBRuntime122) error {
+	if isMDB(bean) {
+		if _, err := wls.domain.rest.Get(ctx, path+"?links=none&fields="+strings.Join(mdbFields, ","), &bean.MDBRuntime122); err != nil {
+			return err
+		}
+	}
+	for _, runtime := range ejbRuntimes[bean.Type.String()] {
+		var response interface{}
+		var fields []string
+		switch runtime {
+		case "poolRuntime":
+			bean.PoolRuntime = &EJBPoolRuntime122{}
+			response, fields = bean.PoolRuntime, metricNames(EJBPoolRuntime122{})
+		case "cacheRuntime":
+			bean.CacheRuntime = &EJBCacheRuntime122{}
+			response, fields = bean.CacheRuntime, metricNames(EJBCacheRuntime122{})
+		case "lockingRuntime":
+			bean.LockingRuntime = &EJBLockingRuntime122{}
+			response, fields = bean.LockingRuntime, metricNames(EJBLockingRuntime122{})
+		case "transactionRuntime":
+			bean.TransactionRuntime = &EJBTransactionRuntime122{}
+			response, fields = bean.TransactionRuntime, metricNames(EJBTransactionRuntime122{})
+		}
+		if _, err := wls.domain.rest.Get(ctx, path+"/"+runtime+"?links=none&fields="+strings.Join(fields, ","), response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (wls *Weblogic122) EJBSearch(query *SearchQuery, inv *Inventory) {
+	applications := inv.AllApplications()
+	if len(applications) == 0 {
+		return
+	}
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	beans := servers.Child("applicationRuntimes", "name").
+		Names(applications...).
+		Child("componentRuntimes", ejbModuleFields...).
+		Child("EJBRuntimes", append(ejbFields, mdbFields...)...)
+	beans.Child("poolRuntime", metricNames(EJBPoolRuntime122{})...)
+	beans.Child("cacheRuntime", metricNames(EJBCacheRuntime122{})...)
+	beans.Child("lockingRuntime", metricNames(EJBLockingRuntime122{})...)
+	beans.Child("transactionRuntime", metricNames(EJBTransactionRuntime122{})...)
+}
+
+func (wls *Weblogic122) EJBSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		for _, application := range inv.Applications[server_name] {
+			if server == nil || server.Application(application) == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "ejb_status", searchNotFound("application", application)))
+				continue
+			}
+			components := server.Application(application).ComponentRuntimes.Items
+			for i := range components {
+				if isEJBModule(&components[i]) {
+					cycle.Add(wls.ejbEvents(server_name, application, &components[i])...)
+				}
+			}
+		}
+	}
+}
+
+func isEJBModule(module *ComponentRuntime122) bool {
+	return module.Type.String() == "EJBComponentRuntime"
+}
+
+func isMDB(bean *EJBRuntime122) bool {
+	return bean.Type.String() == "MessageDrivenEJBRuntime"
+}
+
+// ejbEvents creates an ejb_status event for every bean of an EJB module.
+func (wls *Weblogic122) ejbEvents(server_name string, application string, module *ComponentRuntime122) []beat.Event {
+	events := []beat.Event{}
+	if module.EJBRuntimes == nil {
+		return events
+	}
+
+	for _, bean := range module.EJBRuntimes.Items {
+		ejb_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":         server_name,
+				"wb_metric_type":    "ejb_status",
+				"ejb_server":        server_name,
+				"ejb_application":   application,
+				"ejb_componentName": module.ComponentName.Value(),
+				"ejb_name":          bean.EJBName.Value(),
+				"ejb_type":          bean.Type.Value(),
+			},
+		}
+		if bean.PoolRuntime != nil {
+			ejb_status_event.Fields.Update(metricFields("ejb_pool_", bean.PoolRuntime))
+		}
+		if bean.CacheRuntime != nil {
+			ejb_status_event.Fields.Update(metricFields("ejb_cache_", bean.CacheRuntime))
+		}
+		if bean.TransactionRuntime != nil {
+			ejb_status_event.Fields.Update(metricFields("ejb_tx_", bean.TransactionRuntime))
+		}
+		if bean.LockingRuntime != nil {
+			ejb_status_event.Fields.Update(metricFields("ejb_lock_", bean.LockingRuntime))
+		}
+		if isMDB(&bean) {
+			ejb_status_event.Fields.Update(mdbFieldValues(&bean.MDBRuntime122))
+		}
+		addFieldErrors(ejb_status_event.Fields, responseErrors(&bean))
+		events = append(events, ejb_status_event)
+	}
+	logp.Info("EJB status %s %s - event sent", server_name, module.ComponentName.String())
+	return events
+}
+
+// mdbFieldValues returns the status of a message driven bean, with the
+// connection to its JMS destination or resource adapter.
+func mdbFieldValues(mdb *MDBRuntime122) common.MapStr {
+	fields := common.MapStr{
+		"ejb_MDBStatus":        mdb.MDBStatus.Value(),
+		"ejb_connectionStatus": mdb.ConnectionStatus.Value(),
+	}
+	if mdb.HealthState != nil {
+		fields["ejb_health"] = mdb.HealthState.State.Value()
+		fields["ejb_symptoms"] = mdb.HealthState.Symptoms.String()
+	}
+	fields.Update(metricFields("ejb_", mdb))
+	return fields
+}
@@ -0,0 +1,126 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("jms", (*Weblogic122).JMSStatusEvent, (*Weblogic122).JMSSearch, (*Weblogic122).JMSSearchEvent)
+}
+
+func (wls *Weblogic122) JMSStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		for _, jms_server := range inv.JMSServers[server_name] {
+			server_name, jms_server := server_name, jms_server
+			cycle.Go(server_name, "jms_server_status", func() []beat.Event {
+				return wls.jmsStatus(cycle.Context(), server_name, jms_server)
+			})
+		}
+	}
+}
+
+func (wls *Weblogic122) jmsStatus(ctx context.Context, server_name string, jms_server string) []beat.Event {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/JMSRuntime/JMSServers/" + jms_server
+
+	jms := JMSServerRuntime122{}
+	jms_errors, err_jms := wls.domain.rest.Get(ctx, base+"?links=none&fields="+strings.Join(metricNames(JMSServerRuntime122{}), ","), &jms)
+	if err_jms != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "jms_server_status", err_jms)}
+	}
+
+	destinations := JMSDestinations122{}
+	if _, err_dest := wls.domain.rest.Get(ctx, base+"/destinations?links=none&fields="+strings.Join(metricNames(JMSDestinationRuntime122{}), ","), &destinations); err_dest != nil {
+		return []beat.Event{
+			wls.jmsServerEvent(server_name, jms_server, &jms, jms_errors),
+			wls.domain.ErrorEvent(server_name, "jms_destination_status", err_dest),
+		}
+	}
+
+	return wls.jmsEvents(server_name, jms_server, &jms, &destinations, jms_errors)
+}
+
+func (wls *Weblogic122) JMSSearch(query *SearchQuery, inv *Inventory) {
+	jms_servers := inv.AllJMSServers()
+	if len(jms_servers) == 0 {
+		return
+	}
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("JMSRuntime").
+		Child("JMSServers", metricNames(JMSServerRuntime122{})...).
+		Names(jms_servers...).
+		Child("destinations", metricNames(JMSDestinationRuntime122{})...)
+}
+
+func (wls *Weblogic122) JMSSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		for _, jms_server := range inv.JMSServers[server_name] {
+			if server == nil || server.JMSServer(jms_server) == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "jms_server_status", searchNotFound("JMS server", jms_server)))
+				continue
+			}
+			jms := server.JMSServer(jms_server)
+			cycle.Add(wls.jmsEvents(server_name, jms_server, &jms.JMSServerRuntime122, &jms.Destinations, responseErrors(&jms.JMSServerRuntime122))...)
+		}
+	}
+}
+
+// jmsEvents creates the event of a JMS server followed by the events of its
+// destinations in the configured JMS modules.
+func (wls *Weblogic122) jmsEvents(server_name string, jms_server string, jms *JMSServerRuntime122, destinations *JMSDestinations122, jms_errors []string) []beat.Event {
+	events := []beat.Event{wls.jmsServerEvent(server_name, jms_server, jms, jms_errors)}
+
+	for _, destination := range destinations.Items {
+		// Destinations are named <module>!<destination>
+		module := ""
+		if parts := strings.SplitN(destination.Name.String(), "!", 2); len(parts) == 2 {
+			module = parts[0]
+		}
+		if len(wls.config.JMSModules) > 0 && !stringInSlice(module, wls.config.JMSModules) {
+			continue
+		}
+
+		destination_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":      server_name,
+				"wb_metric_type": "jms_destination_status",
+				"jmsd_server":    server_name,
+				"jmsd_jmsserver": jms_server,
+				"jmsd_module":    module,
+				"jmsd_name":      destination.Name.Value(),
+				"jmsd_type":      destination.DestinationType.Value(),
+			},
+		}
+		destination_status_event.Fields.Update(metricFields("jmsd_", destination))
+		addFieldErrors(destination_status_event.Fields, responseErrors(destination))
+		events = append(events, destination_status_event)
+	}
+	logp.Info("JMS status %s %s - event sent", server_name, jms_server)
+	return events
+}
+
+func (wls *Weblogic122) jmsServerEvent(server_name string, jms_server string, jms *JMSServerRuntime122, jms_errors []string) beat.Event {
+	jms_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":      server_name,
+			"wb_metric_type": "jms_server_status",
+			"jms_server":     server_name,
+			"jms_name":       jms_server,
+			"jms_health":     jms.HealthState.State.Value(),
+			"jms_symptoms":   jms.HealthState.Symptoms.String(),
+		},
+	}
+	jms_status_event.Fields.Update(metricFields("jms_", jms))
+	addFieldErrors(jms_status_event.Fields, jms_errors)
+	return jms_status_event
+}
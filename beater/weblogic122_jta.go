@@ -0,0 +1,69 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("transaction", (*Weblogic122).TransactionStatusEvent, (*Weblogic122).TransactionSearch, (*Weblogic122).TransactionSearchEvent)
+}
+
+func (wls *Weblogic122) TransactionStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "transaction_status", func() []beat.Event {
+			return wls.transactionStatus(cycle.Context(), server_name)
+		})
+	}
+}
+
+func (wls *Weblogic122) transactionStatus(ctx context.Context, server_name string) []beat.Event {
+	jta := JTARuntime122{}
+	jta_errors, err_jta := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/JTARuntime?links=none&fields="+strings.Join(metricNames(JTARuntime122{}), ","), &jta)
+	if err_jta != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "transaction_status", err_jta)}
+	}
+
+	return []beat.Event{wls.transactionEvent(server_name, &jta, jta_errors)}
+}
+
+func (wls *Weblogic122) TransactionSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("JTARuntime", metricNames(JTARuntime122{})...)
+}
+
+func (wls *Weblogic122) TransactionSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "transaction_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.transactionEvent(server_name, &server.JTARuntime, responseErrors(&server.JTARuntime)))
+	}
+}
+
+func (wls *Weblogic122) transactionEvent(server_name string, jta *JTARuntime122, jta_errors []string) beat.Event {
+	transaction_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":      server_name,
+			"wb_metric_type": "transaction_status",
+			"jta_server":     server_name,
+			"jta_health":     jta.HealthState.State.Value(),
+			"jta_symptoms":   jta.HealthState.Symptoms.String(),
+		},
+	}
+	transaction_status_event.Fields.Update(metricFields("jta_", jta))
+	addFieldErrors(transaction_status_event.Fields, jta_errors)
+	logp.Info("Transaction status %s - event sent", server_name)
+	return transaction_status_event
+}
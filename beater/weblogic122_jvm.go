@@ -0,0 +1,72 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("jvm", (*Weblogic122).JVMStatusEvent, (*Weblogic122).JVMSearch, (*Weblogic122).JVMSearchEvent)
+}
+
+func (wls *Weblogic122) JVMStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "jvm_status", func() []beat.Event {
+			return wls.jvmStatus(cycle.Context(), server_name)
+		})
+	}
+}
+
+func (wls *Weblogic122) jvmStatus(ctx context.Context, server_name string) []beat.Event {
+	jvm := JVMStatus122{}
+	jvm_errors, err_jvm := wls.domain.rest.Get(ctx, "/management/weblogic/latest/domainRuntime/serverRuntimes/"+server_name+"/JVMRuntime?links=none&fields="+strings.Join(metricNames(JVMStatus122{}), ","), &jvm)
+	if err_jvm != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "jvm_status", err_jvm)}
+	}
+
+	return []beat.Event{wls.jvmEvent(server_name, &jvm, jvm_errors)}
+}
+
+func (wls *Weblogic122) JVMSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("JVMRuntime", metricNames(JVMStatus122{})...)
+}
+
+func (wls *Weblogic122) JVMSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "jvm_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.jvmEvent(server_name, &server.JVMRuntime, responseErrors(&server.JVMRuntime)))
+	}
+}
+
+func (wls *Weblogic122) jvmEvent(server_name string, jvm *JVMStatus122, jvm_errors []string) beat.Event {
+	jvm_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":        server_name,
+			"wb_metric_type":   "jvm_status",
+			"jvm_server":       server_name,
+			"jvm_javaVendor":   jvm.JavaVendor.Value(),
+			"jvm_javaVersion":  jvm.JavaVersion.Value(),
+			"jvm_javaVMVendor": jvm.JavaVMVendor.Value(),
+			"jvm_OSName":       jvm.OSName.Value(),
+			"jvm_OSVersion":    jvm.OSVersion.Value(),
+		},
+	}
+	jvm_status_event.Fields.Update(metricFields("jvm_", jvm))
+	addFieldErrors(jvm_status_event.Fields, jvm_errors)
+	logp.Info("JVM status %s - event sent", server_name)
+	return jvm_status_event
+}
@@ -0,0 +1,123 @@
+package beater
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("servlet", (*Weblogic122).ServletStatusEvent, (*Weblogic122).ServletSearch, (*Weblogic122).ServletSearchEvent)
+}
+
+// Fields of the web modules read by the servlet collector.
+var webModuleFields = []string{"name", "type", "componentName", "status", "contextRoot", "deploymentState"}
+
+func (wls *Weblogic122) ServletStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		for _, application := range inv.Applications[server_name] {
+			server_name, application := server_name, application
+			cycle.Go(server_name, "servlet_status", func() []beat.Event {
+				return wls.servletStatus(cycle.Context(), server_name, application)
+			})
+		}
+	}
+}
+
+func (wls *Weblogic122) servletStatus(ctx context.Context, server_name string, application string) []beat.Event {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name + "/applicationRuntimes/" + application + "/componentRuntimes"
+
+	components := ComponentRuntimes122{}
+	if _, err_comp := wls.domain.rest.Get(ctx, base+"?links=none&fields="+strings.Join(webModuleFields, ","), &components); err_comp != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "servlet_status", err_comp)}
+	}
+
+	events := []beat.Event{}
+	for i := range components.Items {
+		module := &components.Items[i]
+		if !isWebModule(module) {
+			continue
+		}
+		// Module names like <server>_/<context root> have to be escaped
+		servlets := Servlets122{}
+		if _, err_servlets := wls.domain.rest.Get(ctx, base+"/"+url.PathEscape(module.Name.String())+"/servlets?links=none&fields="+strings.Join(metricNames(ServletRuntime122{}), ","), &servlets); err_servlets != nil {
+			events = append(events, wls.domain.ErrorEvent(server_name, "servlet_status", err_servlets))
+			continue
+		}
+		module.Servlets = &servlets
+		events = append(events, wls.servletEvents(server_name, application, module)...)
+	}
+	return events
+}
+
+func (wls *Weblogic122) ServletSearch(query *SearchQuery, inv *Inventory) {
+	applications := inv.AllApplications()
+	if len(applications) == 0 {
+		return
+	}
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	servers.Child("applicationRuntimes", "name").
+		Names(applications...).
+		Child("componentRuntimes", webModuleFields...).
+		Child("servlets", metricNames(ServletRuntime122{})...)
+}
+
+func (wls *Weblogic122) ServletSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		for _, application := range inv.Applications[server_name] {
+			if server == nil || server.Application(application) == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "servlet_status", searchNotFound("application", application)))
+				continue
+			}
+			components := server.Application(application).ComponentRuntimes.Items
+			for i := range components {
+				if isWebModule(&components[i]) {
+					cycle.Add(wls.servletEvents(server_name, application, &components[i])...)
+				}
+			}
+		}
+	}
+}
+
+func isWebModule(module *ComponentRuntime122) bool {
+	return module.Type.String() == "WebAppComponentRuntime"
+}
+
+// servletEvents creates a servlet_status event for every servlet of a web
+// module.
+func (wls *Weblogic122) servletEvents(server_name string, application string, module *ComponentRuntime122) []beat.Event {
+	events := []beat.Event{}
+	if module.Servlets == nil {
+		return events
+	}
+
+	for _, servlet := range module.Servlets.Items {
+		servlet_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":          server_name,
+				"wb_metric_type":     "servlet_status",
+				"sv_server":          server_name,
+				"sv_application":     application,
+				"sv_componentName":   module.ComponentName.Value(),
+				"sv_contextRoot":     module.ContextRoot.Value(),
+				"sv_deploymentState": module.DeploymentState.Long(),
+				"sv_moduleStatus":    module.Status.Value(),
+				"sv_name":            servlet.ServletName.Value(),
+			},
+		}
+		servlet_status_event.Fields.Update(metricFields("sv_", servlet))
+		addFieldErrors(servlet_status_event.Fields, responseErrors(servlet))
+		events = append(events, servlet_status_event)
+	}
+	logp.Info("Servlet status %s %s - event sent", server_name, module.ComponentName.String())
+	return events
+}
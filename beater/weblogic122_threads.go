@@ -0,0 +1,124 @@
+package beater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// threadsBusy returns true when the thread pool of a server has stuck or
+// hogging threads.
+func threadsBusy(threads *ThreadPoolRuntime122) bool {
+	stuck, _ := threads.StuckThreadCount.Long().(int64)
+	hogging, _ := threads.HoggingThreadCount.Long().(int64)
+	return stuck > 0 || hogging > 0
+}
+
+// threadDetails creates a thread_detail event for every stuck or hogging
+// thread of a server not reported in a previous cycle.
+func (wls *Weblogic122) threadDetails(ctx context.Context, server_name string) []beat.Event {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name
+
+	threads := ExecuteThreads122{}
+	if _, err_threads := wls.domain.rest.Get(ctx, base+"/threadPoolRuntime?links=none&fields=executeThreads", &threads); err_threads != nil {
+		return []beat.Event{wls.domain.ErrorEvent(server_name, "thread_detail", err_threads)}
+	}
+
+	busy := map[string]ExecuteThread122{}
+	keys := []string{}
+	for _, thread := range threads.ExecuteThreads {
+		if thread.Stuck.Value() != true && thread.Hogger.Value() != true {
+			continue
+		}
+		// A thread is reported again when it is busy with another request
+		key := fmt.Sprintf("%s|%v", thread.Name.String(), thread.CurrentRequestStartTime.Long())
+		busy[key] = thread
+		keys = append(keys, key)
+	}
+
+	added := wls.domain.threads.Update(server_name, keys)
+	if len(added) == 0 {
+		return []beat.Event{}
+	}
+
+	dump := ""
+	if wls.config.ThreadDetails.ThreadDump {
+		thread_dump := ThreadDump122{}
+		if _, err_dump := wls.domain.rest.Get(ctx, base+"/JVMRuntime?links=none&fields=threadStackDump", &thread_dump); err_dump != nil {
+			logp.Warn("Error reading thread dump of %s: %v", server_name, err_dump)
+		}
+		dump = thread_dump.ThreadStackDump.String()
+	}
+
+	events := []beat.Event{}
+	for _, key := range added {
+		thread := busy[key]
+		state := "hogging"
+		if thread.Stuck.Value() == true {
+			state = "stuck"
+		}
+
+		thread_detail_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":          server_name,
+				"wb_metric_type":     "thread_detail",
+				"thd_server":         server_name,
+				"thd_name":           thread.Name.Value(),
+				"thd_state":          state,
+				"thd_workManager":    thread.WorkManagerName.Value(),
+				"thd_application":    thread.ApplicationName.Value(),
+				"thd_module":         thread.ModuleName.Value(),
+				"thd_currentRequest": thread.CurrentRequest.Value(),
+				"thd_user":           thread.User.Value(),
+				"thd_transaction":    thread.Transaction.Value(),
+			},
+		}
+		if start, ok := thread.CurrentRequestStartTime.Long().(int64); ok && start > 0 {
+			thread_detail_event.Fields["thd_busy_ms"] = time.Now().UnixNano()/int64(time.Millisecond) - start
+		}
+		if stack := threadStack(dump, thread.Name.String()); stack != "" {
+			thread_detail_event.Fields["thd_stack"] = stack
+		}
+		addFieldErrors(thread_detail_event.Fields, responseErrors(thread))
+		events = append(events, thread_detail_event)
+	}
+	logp.Info("Thread details %s - %d events sent", server_name, len(events))
+	return events
+}
+
+// threadStack returns the stack of a thread from a thread dump, where the
+// threads are separated by blank lines and start with their quoted name.
+func threadStack(dump string, name string) string {
+	if dump == "" || name == "" {
+		return ""
+	}
+	for _, block := range strings.Split(dump, "\n\n") {
+		block = strings.TrimLeft(block, "\n")
+		if !strings.HasPrefix(block, "\"") {
+			continue
+		}
+		quoted := strings.SplitN(block[1:], "\"", 2)[0]
+		if threadName(quoted) == threadName(name) {
+			return block
+		}
+	}
+	return ""
+}
+
+// threadName removes the state weblogic adds to the name of its execute
+// threads, like [STUCK], which may have changed between the thread list and
+// the dump.
+func threadName(name string) string {
+	if strings.HasPrefix(name, "[") {
+		if end := strings.Index(name, "] "); end > 0 {
+			return name[end+2:]
+		}
+	}
+	return name
+}
@@ -0,0 +1,85 @@
+// +build !integration
+
+package beater
+
+import (
+	"testing"
+)
+
+const threadDump = `===== FULL THREAD DUMP ===============
+Thu Oct 15 10:12:03 CEST 2026
+Oracle JRockit(R) R28.3.2-14-160877-1.6.0_75-20140321-2359-linux-x86_64
+
+"[STUCK] ExecuteThread: '1' for queue: 'weblogic.kernel.Default (self-tuning)'" id=21 idx=0x54 tid=6101 prio=1 alive, sleeping, native_waiting, daemon
+    at java/lang/Thread.sleep(J)V(Native Method)
+    at com/example/SlowServlet.doGet(SlowServlet.java:42)
+
+"[ACTIVE] ExecuteThread: '10' for queue: 'weblogic.kernel.Default (self-tuning)'" id=30 idx=0x78 tid=6120 prio=5 alive, in native, daemon
+    at jrockit/net/SocketNativeIO.readBytesPinned(Ljava/io/FileDescriptor;[BIII)I(Native Method)
+
+"Timer-2" id=40 idx=0x90 tid=6130 prio=5 alive, waiting, native_blocked, daemon
+    at java/lang/Object.wait(J)V(Native Method)
+`
+
+func TestThreadStack(t *testing.T) {
+	tests := []struct {
+		name   string
+		dump   string
+		thread string
+		want   string
+	}{
+		{
+			name:   "stuck thread",
+			dump:   threadDump,
+			thread: "[STUCK] ExecuteThread: '1' for queue: 'weblogic.kernel.Default (self-tuning)'",
+			want: `"[STUCK] ExecuteThread: '1' for queue: 'weblogic.kernel.Default (self-tuning)'" id=21 idx=0x54 tid=6101 prio=1 alive, sleeping, native_waiting, daemon
+    at java/lang/Thread.sleep(J)V(Native Method)
+    at com/example/SlowServlet.doGet(SlowServlet.java:42)`,
+		},
+		{
+			name:   "thread with a longer number",
+			dump:   threadDump,
+			thread: "[ACTIVE] ExecuteThread: '10' for queue: 'weblogic.kernel.Default (self-tuning)'",
+			want: `"[ACTIVE] ExecuteThread: '10' for queue: 'weblogic.kernel.Default (self-tuning)'" id=30 idx=0x78 tid=6120 prio=5 alive, in native, daemon
+    at jrockit/net/SocketNativeIO.readBytesPinned(Ljava/io/FileDescriptor;[BIII)I(Native Method)`,
+		},
+		{
+			name:   "last thread of the dump",
+			dump:   threadDump,
+			thread: "Timer-2",
+			want: `"Timer-2" id=40 idx=0x90 tid=6130 prio=5 alive, waiting, native_blocked, daemon
+    at java/lang/Object.wait(J)V(Native Method)
+`,
+		},
+		{
+			name:   "state changed since the thread list",
+			dump:   threadDump,
+			thread: "[HOGGING] ExecuteThread: '10' for queue: 'weblogic.kernel.Default (self-tuning)'",
+			want: `"[ACTIVE] ExecuteThread: '10' for queue: 'weblogic.kernel.Default (self-tuning)'" id=30 idx=0x78 tid=6120 prio=5 alive, in native, daemon
+    at jrockit/net/SocketNativeIO.readBytesPinned(Ljava/io/FileDescriptor;[BIII)I(Native Method)`,
+		},
+		{
+			name:   "name that is only part of another",
+			dump:   threadDump,
+			thread: "ExecuteThread: '1'",
+			want:   "",
+		},
+		{
+			name:   "thread not in the dump",
+			dump:   threadDump,
+			thread: "[STUCK] ExecuteThread: '2' for queue: 'weblogic.kernel.Default (self-tuning)'",
+			want:   "",
+		},
+		{
+			name:   "no dump",
+			dump:   "",
+			thread: "Timer-2",
+			want:   "",
+		},
+	}
+	for _, test := range tests {
+		if got := threadStack(test.dump, test.thread); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
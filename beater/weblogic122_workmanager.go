@@ -0,0 +1,159 @@
+package beater
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	registerWeblogic122Search("workmanager", (*Weblogic122).WorkManagerStatusEvent, (*Weblogic122).WorkManagerSearch, (*Weblogic122).WorkManagerSearchEvent)
+}
+
+// WorkManagerStatusEvent collects the global work managers of every server
+// and the work managers of its applications.
+func (wls *Weblogic122) WorkManagerStatusEvent(cycle *Cycle) {
+
+	inv := wls.domain.Inventory()
+	for _, server_name := range inv.ServerNames {
+		server_name := server_name
+		cycle.Go(server_name, "workmanager_status", func() []beat.Event {
+			return wls.workManagerStatus(cycle.Context(), server_name, "")
+		})
+		for _, application := range inv.Applications[server_name] {
+			application := application
+			cycle.Go(server_name, "workmanager_status", func() []beat.Event {
+				return wls.workManagerStatus(cycle.Context(), server_name, application)
+			})
+		}
+	}
+}
+
+// workManagerStatus reads the work managers of a server, or of one of its
+// applications when application is set. A failed request only leaves out the
+// events of its collection.
+func (wls *Weblogic122) workManagerStatus(ctx context.Context, server_name string, application string) []beat.Event {
+	base := "/management/weblogic/latest/domainRuntime/serverRuntimes/" + server_name
+	if application != "" {
+		base += "/applicationRuntimes/" + application
+	}
+
+	set := WorkManagerSet122{}
+	requests := []struct {
+		path     string
+		fields   interface{}
+		response interface{}
+	}{
+		{"workManagerRuntimes", WorkManagerRuntime122{}, &set.WorkManagerRuntimes},
+		{"minThreadsConstraintRuntimes", MinThreadsConstraintRuntime122{}, &set.MinThreadsConstraintRuntimes},
+		{"maxThreadsConstraintRuntimes", MaxThreadsConstraintRuntime122{}, &set.MaxThreadsConstraintRuntimes},
+		{"requestClassRuntimes", RequestClassRuntime122{}, &set.RequestClassRuntimes},
+	}
+	error_events := []beat.Event{}
+	for _, request := range requests {
+		if _, err_wm := wls.domain.rest.Get(ctx, base+"/"+request.path+"?links=none&fields="+strings.Join(metricNames(request.fields), ","), request.response); err_wm != nil {
+			error_events = append(error_events, wls.domain.ErrorEvent(server_name, "workmanager_status", err_wm))
+		}
+	}
+
+	return append(wls.workManagerEvents(server_name, application, &set), error_events...)
+}
+
+func (wls *Weblogic122) WorkManagerSearch(query *SearchQuery, inv *Inventory) {
+	servers := query.Child("serverRuntimes", "name").Names(inv.ServerNames...)
+	searchWorkManagers(servers)
+
+	applications := inv.AllApplications()
+	if len(applications) > 0 {
+		searchWorkManagers(servers.Child("applicationRuntimes", "name").Names(applications...))
+	}
+}
+
+func searchWorkManagers(node *SearchQuery) {
+	node.Child("workManagerRuntimes", metricNames(WorkManagerRuntime122{})...)
+	node.Child("minThreadsConstraintRuntimes", metricNames(MinThreadsConstraintRuntime122{})...)
+	node.Child("maxThreadsConstraintRuntimes", metricNames(MaxThreadsConstraintRuntime122{})...)
+	node.Child("requestClassRuntimes", metricNames(RequestClassRuntime122{})...)
+}
+
+func (wls *Weblogic122) WorkManagerSearchEvent(cycle *Cycle, result *SearchResult122, inv *Inventory) {
+	for _, server_name := range inv.ServerNames {
+		server := result.Server(server_name)
+		if server == nil {
+			cycle.Add(wls.domain.ErrorEvent(server_name, "workmanager_status", searchNotFound("server", server_name)))
+			continue
+		}
+		cycle.Add(wls.workManagerEvents(server_name, "", &server.WorkManagerSet122)...)
+
+		for _, application := range inv.Applications[server_name] {
+			appinfo := server.Application(application)
+			if appinfo == nil {
+				cycle.Add(wls.domain.ErrorEvent(server_name, "workmanager_status", searchNotFound("application", application)))
+				continue
+			}
+			cycle.Add(wls.workManagerEvents(server_name, application, &appinfo.WorkManagerSet122)...)
+		}
+	}
+}
+
+// workManagerEvents creates a workmanager_status event for every work manager
+// and a workmanager_constraint_status event for every constraint and request
+// class.
+func (wls *Weblogic122) workManagerEvents(server_name string, application string, set *WorkManagerSet122) []beat.Event {
+	events := []beat.Event{}
+
+	for _, wm := range set.WorkManagerRuntimes.Items {
+		workmanager_status_event := beat.Event{
+			Timestamp: time.Now(),
+			Fields: common.MapStr{
+				"wb_server":      server_name,
+				"wb_metric_type": "workmanager_status",
+				"wm_server":      server_name,
+				"wm_application": application,
+				"wm_module":      wm.ModuleName.Value(),
+				"wm_name":        wm.Name.Value(),
+				"wm_health":      wm.HealthState.State.Value(),
+				"wm_symptoms":    wm.HealthState.Symptoms.String(),
+			},
+		}
+		workmanager_status_event.Fields.Update(metricFields("wm_", wm))
+		addFieldErrors(workmanager_status_event.Fields, responseErrors(wm))
+		events = append(events, workmanager_status_event)
+	}
+
+	for _, constraint := range set.MinThreadsConstraintRuntimes.Items {
+		events = append(events, wls.constraintEvent(server_name, application, "min_threads", constraint.Name, constraint))
+	}
+	for _, constraint := range set.MaxThreadsConstraintRuntimes.Items {
+		events = append(events, wls.constraintEvent(server_name, application, "max_threads", constraint.Name, constraint))
+	}
+	for _, request_class := range set.RequestClassRuntimes.Items {
+		event := wls.constraintEvent(server_name, application, "request_class", request_class.Name, request_class)
+		event.Fields["wmc_requestClassType"] = request_class.RequestClassType.Value()
+		events = append(events, event)
+	}
+
+	logp.Info("Work manager status %s %s - event sent", server_name, application)
+	return events
+}
+
+func (wls *Weblogic122) constraintEvent(server_name string, application string, constraint_type string, name String, constraint interface{}) beat.Event {
+	constraint_status_event := beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"wb_server":       server_name,
+			"wb_metric_type":  "workmanager_constraint_status",
+			"wmc_server":      server_name,
+			"wmc_application": application,
+			"wmc_type":        constraint_type,
+			"wmc_name":        name.Value(),
+		},
+	}
+	constraint_status_event.Fields.Update(metricFields("wmc_", constraint))
+	addFieldErrors(constraint_status_event.Fields, responseErrors(constraint))
+	return constraint_status_event
+}
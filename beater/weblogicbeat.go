@@ -1,23 +1,23 @@
 package beater
 
 import (
-	"crypto/tls"
+	"context"
 	"fmt"
-	"time"
+	"sync"
 
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
 
 	"github.com/carlgira/weblogicbeat/config"
-	resty "gopkg.in/resty.v1"
 )
 
 // Weblogicbeat configuration.
 type Weblogicbeat struct {
-	done   chan struct{}
-	config config.Config
-	client beat.Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	config  config.Config
+	domains []*Domain
 }
 
 // New creates an instance of weblogicbeat.
@@ -27,13 +27,27 @@ func New(b *beat.Beat, cfg *common.Config) (beat.Beater, error) {
 		return nil, fmt.Errorf("Error reading config file: %v", err)
 	}
 
+	domain_configs, err := c.DomainConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading config file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	bt := &Weblogicbeat{
-		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
 		config: c,
 	}
 
-	// FIX add flag as parameter
-	resty.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	// Every domain has its own pool, so the requests to an admin server that
+	// hangs can only hold the workers of its domain.
+	for _, domain_config := range domain_configs {
+		domain, err := NewDomain(domain_config, NewPool(c.Workers, c.MaxRequestsPerHost), c.MaxRequestsPerHost)
+		if err != nil {
+			return nil, fmt.Errorf("Error in domain %s: %v", domain_config.Name, err)
+		}
+		bt.domains = append(bt.domains, domain)
+	}
 
 	return bt, nil
 }
@@ -42,47 +56,31 @@ func New(b *beat.Beat, cfg *common.Config) (beat.Beater, error) {
 func (bt *Weblogicbeat) Run(b *beat.Beat) error {
 	logp.Info("weblogicbeat is running! Hit CTRL-C to stop it.")
 
-	var err error
-	bt.client, err = b.Publisher.Connect()
-	if err != nil {
-		return err
-	}
-
-	ticker := time.NewTicker(bt.config.Period)
-	counter := 1
-	for {
-		select {
-		case <-bt.done:
-			return nil
-		case <-ticker.C:
+	for _, domain := range bt.domains {
+		if err := domain.Connect(b.Publisher); err != nil {
+			return err
 		}
+	}
 
-		if bt.config.WlsVersion == "12.1.2" {
-			wls := &Weblogic1212{
-				bt:     *bt,
-				config: bt.config,
-			}
-			wls.ServerStatusEvent()
-			wls.DatasourceStatusEvent()
-			wls.ApplicationStatusEvent()
-			wls.ThreadStatusEvent()
-
-		} else {
-			wls := &Weblogic122{
-				bt:     *bt,
-				config: bt.config,
-			}
-			wls.ServerStatusEvent()
-			wls.DatasourceStatusEvent()
-			wls.ApplicationStatusEvent()
-			wls.ThreadStatusEvent()
-		}
-		counter++
+	// Every domain is polled by its own goroutine so a slow domain can not
+	// delay the others.
+	var wg sync.WaitGroup
+	for _, domain := range bt.domains {
+		wg.Add(1)
+		go func(domain *Domain) {
+			defer wg.Done()
+			domain.Run(bt.ctx)
+		}(domain)
 	}
+	wg.Wait()
+
+	return nil
 }
 
-// Stop stops weblogicbeat.
+// Stop stops weblogicbeat. The requests in progress are abandoned.
 func (bt *Weblogicbeat) Stop() {
-	bt.client.Close()
-	close(bt.done)
+	bt.cancel()
+	for _, domain := range bt.domains {
+		domain.Close()
+	}
 }
@@ -3,26 +3,297 @@
 
 package config
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/transport/tlscommon"
+)
 
 type Config struct {
-	Period       time.Duration `config:"period"`
-	Host         string        `config:"host"`
-	WlsVersion   string        `config:"wlsversion"`
-	Username     string        `config:"username"`
-	Password     string        `config:"password"`
-	ServerNames  []string      `config:"servernames"`
-	Datasources  []string      `config:"datasources"`
-	Applications []string      `config:"applications"`
+	DomainConfig       `config:",inline"`
+	Domains            []*common.Config `config:"domains"`
+	Workers            int              `config:"workers" validate:"min=1"`
+	MaxRequestsPerHost int              `config:"max_requests_per_host" validate:"min=1"`
+}
+
+// DomainConfig holds the settings of one monitored weblogic domain.
+type DomainConfig struct {
+	Name           string                   `config:"name"`
+	Period         time.Duration            `config:"period"`
+	Host           string                   `config:"host"`
+	WlsVersion     string                   `config:"wlsversion"`
+	Username       string                   `config:"username"`
+	Password       string                   `config:"password"`
+	Timeout        time.Duration            `config:"timeout"`
+	ConnectTimeout time.Duration            `config:"connect_timeout"`
+	KeepAlive      time.Duration            `config:"keep_alive"`
+	ProxyURL       string                   `config:"proxy_url"`
+	Headers        map[string]string        `config:"headers"`
+	TLS            *tlscommon.Config        `config:"ssl"`
+	ServerNames    []string                 `config:"servernames"`
+	Datasources    []string                 `config:"datasources"`
+	Applications   []string                 `config:"applications"`
+	JMSServers     []string                 `config:"jmsservers"`
+	JMSModules     []string                 `config:"jmsmodules"`
+	Collectors     []string                 `config:"collectors"`
+	Periods        map[string]time.Duration `config:"periods"`
+	Discovery      Discovery                `config:"discovery"`
+	Bulk           bool                     `config:"bulk"`
+	HeapMB         bool                     `config:"heap_mb"`
+	TestPool       TestPool                 `config:"testpool"`
+	ThreadDetails  ThreadDetails            `config:"thread_details"`
+	Retry          Retry                    `config:"retry"`
+	Breaker        Breaker                  `config:"circuit_breaker"`
+}
+
+// TestPool tests the connection pool of every datasource at most once every
+// Interval.
+type TestPool struct {
+	Enabled  bool          `config:"enabled"`
+	Interval time.Duration `config:"interval"`
+}
+
+// ThreadDetails publishes an event for every stuck or hogging thread of a
+// server with stuck or hogging threads, with its stack when ThreadDump is set.
+type ThreadDetails struct {
+	Enabled    bool `config:"enabled"`
+	ThreadDump bool `config:"thread_dump"`
+}
+
+// Retry repeats a request failed because of a transport error or a server
+// error, waiting a random time up to Backoff doubled on every attempt and
+// limited by MaxBackoff.
+type Retry struct {
+	MaxRetries int           `config:"max_retries" validate:"min=0"`
+	Backoff    time.Duration `config:"backoff"`
+	MaxBackoff time.Duration `config:"max_backoff"`
+}
+
+// Breaker stops the requests to an admin server for Cooldown after Failures
+// consecutive requests failed.
+type Breaker struct {
+	Enabled  bool          `config:"enabled"`
+	Failures int           `config:"failures" validate:"min=1"`
+	Cooldown time.Duration `config:"cooldown"`
+}
+
+// Discovery replaces the servernames, datasources, applications and jmsservers
+// lists with the ones found on the admin server, refreshed every Refresh.
+type Discovery struct {
+	Enabled      bool          `config:"enabled"`
+	Refresh      time.Duration `config:"refresh"`
+	Servers      Filter        `config:"servers"`
+	Datasources  Filter        `config:"datasources"`
+	Applications Filter        `config:"applications"`
+	JMSServers   Filter        `config:"jmsservers"`
+}
+
+// Filter selects names by glob patterns, or by regular expressions when the
+// pattern is enclosed in slashes (/^ess.*/). An empty include list selects
+// every name.
+type Filter struct {
+	Include []string `config:"include"`
+	Exclude []string `config:"exclude"`
 }
 
 var DefaultConfig = Config{
-	Period:       1 * time.Second,
-	Host:         "",
-	WlsVersion:   "12.2",
-	Username:     "",
-	Password:     "",
-	ServerNames:  []string{},
-	Datasources:  []string{},
-	Applications: []string{},
+	DomainConfig: DomainConfig{
+		Name:           "",
+		Period:         1 * time.Second,
+		Host:           "",
+		WlsVersion:     "",
+		Username:       "",
+		Password:       "",
+		Timeout:        10 * time.Second,
+		ConnectTimeout: 5 * time.Second,
+		KeepAlive:      30 * time.Second,
+		Headers:        map[string]string{},
+		ServerNames:    []string{},
+		Datasources:    []string{},
+		Applications:   []string{},
+		JMSServers:     []string{},
+		JMSModules:     []string{},
+		Collectors:     []string{"server", "datasource", "application", "thread"},
+		Periods:        map[string]time.Duration{},
+		Discovery: Discovery{
+			Enabled: false,
+			Refresh: 5 * time.Minute,
+		},
+		TestPool: TestPool{
+			Enabled:  false,
+			Interval: 5 * time.Minute,
+		},
+		ThreadDetails: ThreadDetails{
+			Enabled:    true,
+			ThreadDump: false,
+		},
+		Retry: Retry{
+			MaxRetries: 2,
+			Backoff:    500 * time.Millisecond,
+			MaxBackoff: 5 * time.Second,
+		},
+		Breaker: Breaker{
+			Enabled:  true,
+			Failures: 5,
+			Cooldown: 1 * time.Minute,
+		},
+	},
+	Workers:            10,
+	MaxRequestsPerHost: 4,
+}
+
+// DomainConfigs returns the settings of every monitored domain. The entries of
+// the domains list inherit the top level settings they don't override, without
+// a domains list the top level settings describe the only domain. Domains
+// without a name are named after their host.
+func (c *Config) DomainConfigs() ([]DomainConfig, error) {
+	if len(c.Domains) == 0 {
+		return []DomainConfig{c.DomainConfig.withName()}, nil
+	}
+
+	domains := []DomainConfig{}
+	names := map[string]bool{}
+	for _, raw := range c.Domains {
+		domain := c.DomainConfig
+		domain.Name = ""
+		domain.Periods = map[string]time.Duration{}
+		for name, period := range c.Periods {
+			domain.Periods[name] = period
+		}
+		domain.Headers = map[string]string{}
+		for name, value := range c.Headers {
+			domain.Headers[name] = value
+		}
+		if c.TLS != nil {
+			tls := *c.TLS
+			domain.TLS = &tls
+		}
+		if err := raw.Unpack(&domain); err != nil {
+			return nil, err
+		}
+		domain = domain.withName()
+
+		if names[domain.Name] {
+			return nil, fmt.Errorf("Duplicated domain %s", domain.Name)
+		}
+		names[domain.Name] = true
+		domains = append(domains, domain)
+	}
+	return domains, nil
+}
+
+// Validate checks the settings every domain needs.
+func (d *DomainConfig) Validate() error {
+	if d.Period <= 0 {
+		return fmt.Errorf("Period must be greater than 0")
+	}
+	if d.Timeout < 0 {
+		return fmt.Errorf("Timeout must not be negative")
+	}
+	if d.ConnectTimeout <= 0 {
+		return fmt.Errorf("Connect timeout must be greater than 0")
+	}
+	if d.ProxyURL != "" {
+		if _, err := url.Parse(d.ProxyURL); err != nil {
+			return fmt.Errorf("Invalid proxy_url %s: %v", d.ProxyURL, err)
+		}
+	}
+	for name, period := range d.Periods {
+		if period <= 0 {
+			return fmt.Errorf("Period of collector %s must be greater than 0", name)
+		}
+	}
+	return nil
+}
+
+// CollectorPeriod returns the period of a collector, the domain period unless
+// the collector has its own.
+func (d DomainConfig) CollectorPeriod(name string) time.Duration {
+	if period, found := d.Periods[name]; found {
+		return period
+	}
+	return d.Period
+}
+
+func (d DomainConfig) withName() DomainConfig {
+	if d.Name == "" {
+		d.Name = d.Host
+	}
+	return d
+}
+
+// Validate checks the refresh interval of an enabled discovery.
+func (d *Discovery) Validate() error {
+	if d.Enabled && d.Refresh <= 0 {
+		return fmt.Errorf("Discovery refresh must be greater than 0")
+	}
+	return nil
+}
+
+// Validate checks the interval of enabled pool tests.
+func (t *TestPool) Validate() error {
+	if t.Enabled && t.Interval <= 0 {
+		return fmt.Errorf("Testpool interval must be greater than 0")
+	}
+	return nil
+}
+
+// Validate checks the backoff of the retries.
+func (r *Retry) Validate() error {
+	if r.MaxRetries > 0 && (r.Backoff <= 0 || r.MaxBackoff < r.Backoff) {
+		return fmt.Errorf("Retry backoff must be greater than 0 and not greater than max_backoff")
+	}
+	return nil
+}
+
+// Validate checks the cool-down of an enabled circuit breaker.
+func (b *Breaker) Validate() error {
+	if b.Enabled && b.Cooldown <= 0 {
+		return fmt.Errorf("Circuit breaker cooldown must be greater than 0")
+	}
+	return nil
+}
+
+// Validate checks that every pattern of the filter compiles.
+func (f *Filter) Validate() error {
+	for _, pattern := range append(f.Include, f.Exclude...) {
+		if _, err := matchPattern(pattern, ""); err != nil {
+			return fmt.Errorf("Invalid pattern %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the name matches an include pattern, or there are
+// none, and no exclude pattern.
+func (f Filter) Match(name string) bool {
+	included := len(f.Include) == 0
+	for _, pattern := range f.Include {
+		if matched, _ := matchPattern(pattern, name); matched {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range f.Exclude {
+		if matched, _ := matchPattern(pattern, name); matched {
+			return false
+		}
+	}
+	return true
+}
+
+func matchPattern(pattern string, name string) (bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.MatchString(pattern[1:len(pattern)-1], name)
+	}
+	return path.Match(pattern, name)
 }
@@ -1,3 +1,162 @@
 // +build !integration
 
 package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/transport/tlscommon"
+)
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		match  map[string]bool
+	}{
+		{
+			name:   "empty filter",
+			filter: Filter{},
+			match:  map[string]bool{"AdminServer": true, "": true},
+		},
+		{
+			name:   "glob include",
+			filter: Filter{Include: []string{"ess_server*"}},
+			match:  map[string]bool{"ess_server1": true, "ess_server": true, "soa_server1": false},
+		},
+		{
+			name:   "regex include",
+			filter: Filter{Include: []string{"/^(ess|soa)_server[0-9]+$/"}},
+			match:  map[string]bool{"ess_server1": true, "soa_server12": true, "ess_server": false, "AdminServer": false},
+		},
+		{
+			name:   "exclude",
+			filter: Filter{Exclude: []string{"Admin*", "/^test/"}},
+			match:  map[string]bool{"AdminServer": false, "testDS": false, "EssDS": true},
+		},
+		{
+			name:   "exclude wins over include",
+			filter: Filter{Include: []string{"*DS"}, Exclude: []string{"Test*"}},
+			match:  map[string]bool{"EssDS": true, "TestDS": false, "EssPool": false},
+		},
+		{
+			name:   "single slash is a glob",
+			filter: Filter{Include: []string{"/"}},
+			match:  map[string]bool{"/": true, "a": false},
+		},
+	}
+	for _, test := range tests {
+		for name, want := range test.match {
+			if got := test.filter.Match(name); got != want {
+				t.Errorf("%s: Match(%q) = %v, want %v", test.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	tests := []struct {
+		filter Filter
+		valid  bool
+	}{
+		{Filter{Include: []string{"ess*", "/^soa/"}}, true},
+		{Filter{Include: []string{"/([a-z/"}}, false},
+	}
+	for _, test := range tests {
+		if err := test.filter.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%v) = %v, want valid %v", test.filter, err, test.valid)
+		}
+	}
+}
+
+func TestDomainConfigsWithoutDomains(t *testing.T) {
+	c := DefaultConfig
+	c.Host = "http://admin:7001"
+
+	domains, err := c.DomainConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].Name != "http://admin:7001" {
+		t.Fatalf("got %+v, want a single domain named after its host", domains)
+	}
+}
+
+func TestDomainConfigsInheritance(t *testing.T) {
+	c := DefaultConfig
+	c.Host = "http://admin:7001"
+	c.Headers = map[string]string{"X-Top": "top"}
+	c.Periods = map[string]time.Duration{"server": 5 * time.Second}
+	c.TLS = &tlscommon.Config{CAs: []string{"top.pem"}}
+	c.Domains = []*common.Config{
+		mustConfig(t, map[string]interface{}{
+			"name":    "prod",
+			"host":    "http://prod:7001",
+			"headers": map[string]interface{}{"X-Prod": "prod"},
+			"periods": map[string]interface{}{"thread": "30s"},
+		}),
+		mustConfig(t, map[string]interface{}{
+			"host": "http://test:7001",
+		}),
+	}
+
+	domains, err := c.DomainConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 {
+		t.Fatalf("got %d domains, want 2", len(domains))
+	}
+	prod, test := domains[0], domains[1]
+
+	if prod.Name != "prod" || test.Name != "http://test:7001" {
+		t.Errorf("names %q and %q, want prod and the host of the second domain", prod.Name, test.Name)
+	}
+	if prod.Headers["X-Top"] != "top" || prod.Headers["X-Prod"] != "prod" {
+		t.Errorf("prod headers %v, want the top level and its own", prod.Headers)
+	}
+	if _, found := test.Headers["X-Prod"]; found || test.Headers["X-Top"] != "top" {
+		t.Errorf("test headers %v, want only the top level ones", test.Headers)
+	}
+	if _, found := c.Headers["X-Prod"]; found {
+		t.Errorf("top level headers %v modified by a domain", c.Headers)
+	}
+	if prod.Periods["server"] != 5*time.Second || prod.Periods["thread"] != 30*time.Second {
+		t.Errorf("prod periods %v, want the top level and its own", prod.Periods)
+	}
+	if _, found := c.Periods["thread"]; found {
+		t.Errorf("top level periods %v modified by a domain", c.Periods)
+	}
+
+	if prod.TLS == nil || test.TLS == nil {
+		t.Fatal("ssl settings not inherited")
+	}
+	if prod.TLS == c.TLS || test.TLS == c.TLS || prod.TLS == test.TLS {
+		t.Error("ssl settings shared instead of copied")
+	}
+	prod.TLS.CAs = []string{"prod.pem"}
+	if c.TLS.CAs[0] != "top.pem" || test.TLS.CAs[0] != "top.pem" {
+		t.Errorf("ssl settings of a domain changed the others: top %v, test %v", c.TLS.CAs, test.TLS.CAs)
+	}
+}
+
+func TestDomainConfigsDuplicatedName(t *testing.T) {
+	c := DefaultConfig
+	c.Domains = []*common.Config{
+		mustConfig(t, map[string]interface{}{"host": "http://admin:7001"}),
+		mustConfig(t, map[string]interface{}{"host": "http://admin:7001"}),
+	}
+	if _, err := c.DomainConfigs(); err == nil {
+		t.Error("duplicated domain names accepted")
+	}
+}
+
+func mustConfig(t *testing.T, from map[string]interface{}) *common.Config {
+	cfg, err := common.NewConfigFrom(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}